@@ -0,0 +1,119 @@
+package main
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/scan"
+	"FileVerication/internal/verify"
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+func main() {
+	var (
+		include, exclude string
+		mapFile          string
+		workers          int
+		quiet            bool
+		pathMap          index.PathMap
+	)
+
+	flag.StringVar(&include, "include", strings.Join(scan.VideoExtensions, ","), "Extensions to check when walking a directory, comma separated (empty for all)")
+	flag.StringVar(&exclude, "exclude", strings.Join(scan.ExcludeExtensions, ","), "Extensions to always skip, comma separated")
+	flag.Var(&pathMap, "map", `rewrite an index path prefix to a local one, e.g. \\192.168.1.1\anime=/mnt/nas/anime (repeatable)`)
+	flag.StringVar(&mapFile, "map-file", "", "File of from=to path mappings, one per line")
+	flag.IntVar(&workers, "workers", 2, "Files hashed at once")
+	flag.BoolVar(&quiet, "quiet", false, "Only print mismatches, errors and the summary")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [-map from=to] [-workers n] <index|directory|file>...\n", os.Args[0])
+		os.Exit(2)
+	}
+	if err := pathMap.LoadFile(mapFile); err != nil {
+		panic(err)
+	}
+
+	c := &checker{
+		out:    bufio.NewWriter(os.Stdout),
+		quiet:  quiet,
+		counts: map[verify.Outcome]int{},
+	}
+
+	items := make(chan index.FileItem)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for fi := range items {
+				c.report(verify.CheckFilenameCRC(context.Background(), fi, nil))
+			}
+		})
+	}
+
+	// Only the paths of index entries are used; the index's own digests
+	// play no part.
+	opts := scan.Options{Include: scan.ParseExtensions(include), Exclude: scan.ParseExtensions(exclude)}
+	for in, err := range scan.Inputs(flag.Args(), opts, pathMap) {
+		if err != nil {
+			c.fail(err)
+			continue
+		}
+		items <- in.Item
+	}
+	close(items)
+	wg.Wait()
+
+	_, _ = fmt.Fprintf(c.out, "%d ok, %d mismatched, %d without a tag, %d errors\n",
+		c.counts[verify.OutcomeOK], c.counts[verify.OutcomeMismatch], c.noTag,
+		c.counts[verify.OutcomeStatError]+c.counts[verify.OutcomeHashError]+c.failed)
+	if err := c.out.Flush(); err != nil {
+		panic(err)
+	}
+	if c.counts[verify.OutcomeMismatch] > 0 || c.counts[verify.OutcomeStatError] > 0 || c.counts[verify.OutcomeHashError] > 0 || c.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+type checker struct {
+	quiet bool
+
+	mu     sync.Mutex
+	out    *bufio.Writer
+	counts map[verify.Outcome]int
+	noTag  int
+	failed int
+}
+
+func (c *checker) report(rec verify.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rec.Outcome == verify.OutcomeNoTag {
+		c.noTag++
+		if !c.quiet {
+			_, _ = fmt.Fprintf(c.out, "NOTAG\t\t%s\n", rec.Path)
+		}
+		return
+	}
+	c.counts[rec.Outcome]++
+	switch rec.Outcome {
+	case verify.OutcomeOK:
+		if !c.quiet {
+			_, _ = fmt.Fprintf(c.out, "OK\t%s\t%s\n", rec.Expected, rec.Path)
+		}
+	case verify.OutcomeMismatch:
+		_, _ = fmt.Fprintf(c.out, "MISMATCH\t%s\t%s\t(computed %s)\n", rec.Expected, rec.Path, rec.Computed)
+	default:
+		_, _ = fmt.Fprintf(c.out, "ERROR\t%s\t%s\t(%s)\n", rec.Expected, rec.Path, rec.Error)
+	}
+}
+
+func (c *checker) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failed++
+	_, _ = fmt.Fprintln(os.Stderr, err)
+}
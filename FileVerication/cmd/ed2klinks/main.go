@@ -0,0 +1,105 @@
+package main
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/scan"
+	"FileVerication/internal/verify"
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var (
+		include, exclude string
+		mapFile          string
+		red              bool
+		pathMap          index.PathMap
+	)
+
+	flag.StringVar(&include, "include", strings.Join(scan.VideoExtensions, ","), "Extensions to link when walking a directory, comma separated (empty for all)")
+	flag.StringVar(&exclude, "exclude", strings.Join(scan.ExcludeExtensions, ","), "Extensions to always skip, comma separated")
+	flag.Var(&pathMap, "map", `rewrite an index path prefix to a local one, e.g. \\192.168.1.1\anime=/mnt/nas/anime (repeatable)`)
+	flag.StringVar(&mapFile, "map-file", "", "File of from=to path mappings, one per line")
+	flag.BoolVar(&red, "red", false, "Use the old eDonkey hash for files that are an exact multiple of 9500 KiB")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [-map from=to] [-red] <index|directory|file>...\n", os.Args[0])
+		os.Exit(2)
+	}
+	if err := pathMap.LoadFile(mapFile); err != nil {
+		panic(err)
+	}
+
+	l := &linker{algorithm: "ED2K", out: bufio.NewWriter(os.Stdout)}
+	if red {
+		l.algorithm = "ED2KRED"
+	}
+
+	opts := scan.Options{Include: scan.ParseExtensions(include), Exclude: scan.ParseExtensions(exclude)}
+	for in, err := range scan.Inputs(flag.Args(), opts, pathMap) {
+		if err != nil {
+			l.fail(err)
+			continue
+		}
+		l.input(in)
+	}
+
+	if err := l.out.Flush(); err != nil {
+		panic(err)
+	}
+	if l.failed > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "%d files could not be linked\n", l.failed)
+		os.Exit(1)
+	}
+}
+
+type linker struct {
+	algorithm string
+	out       *bufio.Writer
+	failed    int
+}
+
+// input prints the link for one file, using the ED2K digest its index
+// records where there is one and hashing the file otherwise.
+func (l *linker) input(in scan.Input) {
+	fi := in.Item
+	if h := fi.Digests(in.Run.Algorithm)[l.algorithm]; h != "" && fi.Length > 0 {
+		l.link(fi.Path, fi.Length, h)
+		return
+	}
+
+	info, err := os.Stat(fi.FSPath())
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	h, err := verify.FileHashHex(fi.FSPath(), l.algorithm, nil)
+	if err != nil {
+		l.fail(fmt.Errorf("%s: %w", fi.Path, err))
+		return
+	}
+	l.link(fi.Path, info.Size(), h)
+}
+
+func (l *linker) link(path string, size int64, hash string) {
+	_, _ = fmt.Fprintf(l.out, "ed2k://|file|%s|%s|%s|/\n", linkName(path), strconv.FormatInt(size, 10), strings.ToLower(hash))
+}
+
+func (l *linker) fail(err error) {
+	l.failed++
+	_, _ = fmt.Fprintln(os.Stderr, err)
+}
+
+// linkName is the file name of an index path, which may use either
+// separator, with the characters that would break the link escaped.
+func linkName(p string) string {
+	if i := strings.LastIndexAny(p, `\/`); i >= 0 {
+		p = p[i+1:]
+	}
+	return strings.NewReplacer("%", "%25", "|", "%7C").Replace(p)
+}
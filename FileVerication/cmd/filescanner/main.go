@@ -1,36 +1,144 @@
 package main
 
 import (
+	"FileVerication/internal/hashalg"
 	"FileVerication/internal/index"
 	"FileVerication/internal/metrics"
 	"FileVerication/internal/progress"
+	"FileVerication/internal/report"
+	"FileVerication/internal/scan"
 	"FileVerication/internal/verify"
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 func main() {
 	defaultPath := "\\\\192.168.1.1\\anime\\AnimeHashIndex.clixml"
-	indexPath := flag.String("index", defaultPath, "path to CLIXML index")
+	indexPath := flag.String("index", defaultPath, "path to CLIXML index or NDJSON journal")
+	algorithm := flag.String("alg", "", "hash algorithm for indexes that don't record one (ExistingFilesIndex.clixml, journal): "+strings.Join(hashalg.Names(), ", "))
+	checkOnly := flag.Bool("check", false, "only load and validate the index, then exit")
+	var pathMap index.PathMap
+	flag.Var(&pathMap, "map", `rewrite an index path prefix to a local one, e.g. \\192.168.1.1\anime=/mnt/nas/anime (repeatable)`)
+	mapFile := flag.String("map-file", "", "file of from=to path mappings, one per line")
+	audit := flag.Bool("audit", false, "list files under the index root that the index does not contain, then exit")
+	auditRoot := flag.String("audit-root", "", "directory to audit instead of the index root (after -map)")
+	checkpointPath := flag.String("checkpoint", "", "keep an append-only log of verified files here, for -resume; removed once a run completes")
+	resume := flag.Bool("resume", false, "skip files the checkpoint already records and merge their results (checkpoint defaults to next to the first -report, or <index>.checkpoint.ndjson)")
+	restart := flag.Bool("restart", false, "discard an existing checkpoint and verify everything again")
+	mismatchesPath := flag.String("mismatches", "", "write the paths of files whose hash did not match to this path (default next to the first -report)")
+	failuresPath := flag.String("failures", "", "write every file that did not verify OK as TSV to this path (default next to the first -report)")
+	var reports report.Targets
+	flag.Var(&reports, "report", "write a report as format:path, format one of json, csv, junit, html (repeatable, or comma separated)")
+	fallback := flag.Bool("resolve-fallback", false, "look up paths that fail to stat ignoring case and Unicode normalization")
+	var sample verify.SampleOptions
+	flag.Float64Var(&sample.Percent, "sample-percent", 0, "verify a random sample of this percentage of files")
+	flag.IntVar(&sample.Count, "sample-count", 0, "verify a random sample of this many files")
+	flag.Uint64Var(&sample.Seed, "seed", 1, "seed for -sample-percent and -sample-count; the same seed picks the same files")
+	var maxBytes byteSize
+	flag.Var(&maxBytes, "max-bytes", "stop after hashing this much data, e.g. 500G")
+	maxTime := flag.Duration("max-time", 0, "stop taking new files after this long, e.g. 2h")
+	flag.DurationVar(maxTime, "budget", 0, "same as -max-time")
+	rolling := flag.Bool("rolling", false, "verify the least recently verified files first, per the -history store")
+	historyPath := flag.String("history", "", "per-file store of when each file last verified, kept up to date by this run (default <index>.history.ndjson with -rolling)")
+	corruptionRate := flag.Float64("corruption-rate", 0.001, "fraction of corrupt files, between 0 and 1, to state confidence against in the coverage figures")
+	workers := flag.Int("workers", 2, "files hashed in parallel")
+	perMount := flag.Int("per-mount", 0, "at most this many files hashed at once per share or drive (0 = no cap)")
+	rateMBps := flag.Float64("rate-mbps", 0, "read-rate limit in MB/s (0 = unlimited); type a new value and Enter to change it while running")
+	burstMB := flag.Float64("burst-mb", 64, "how far reads may run ahead of -rate-mbps, in MB")
+	readBuffer := byteSize(verify.DefaultBufferSize)
+	flag.Var(&readBuffer, "read-buffer", "size of each read, e.g. 4M")
+	readAhead := flag.Int("read-ahead", verify.DefaultQueueDepth, "reads queued ahead of the hasher per file")
 	flag.Parse()
+	if *corruptionRate <= 0 || *corruptionRate >= 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "-corruption-rate %g: want a fraction between 0 and 1, e.g. 0.001\n", *corruptionRate)
+		os.Exit(2)
+	}
 
-	run, items, err := index.Load(*indexPath)
+	if err := pathMap.LoadFile(*mapFile); err != nil {
+		panic(err)
+	}
+
+	src, err := index.Open(*indexPath)
 	if err != nil {
 		panic(err)
 	}
+	r := index.MapSource(src, pathMap)
+	defer func(r index.Source) {
+		_ = r.Close()
+	}(r)
+
+	run := r.Run()
+	if *audit {
+		root := *auditRoot
+		if root == "" {
+			root, _ = pathMap.Resolve(run.Root)
+		}
+		if root == "" {
+			panic(fmt.Errorf("%s does not record a root; pass -audit-root", *indexPath))
+		}
+		orphans := auditIndex(r, root)
+		if len(orphans) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
+	switch {
+	case run.Algorithm == "":
+		run.Algorithm = *algorithm
+	case *algorithm != "" && !strings.EqualFold(*algorithm, run.Algorithm):
+		panic(fmt.Errorf("-alg %s conflicts with index algorithm %s", *algorithm, run.Algorithm))
+	}
+	if run.Algorithm == "" {
+		panic(fmt.Errorf("%s does not record a hash algorithm; pass -alg", *indexPath))
+	}
+	fillLengths := run.Format == index.FormatHashtable
+
+	if *checkOnly {
+		v := index.NewValidator(run)
+		for fi, err := range r.All() {
+			if err != nil {
+				panic(err)
+			}
+			v.Add(fi)
+		}
+		problems := v.Finish(r.Run())
+		printProblems(problems)
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("format:", run.Format)
 	fmt.Println("meta:", run.Meta)
 	fmt.Println("algorithm:", run.Algorithm)
-	fmt.Println("items count:", len(items))
+	for _, pm := range pathMap {
+		fmt.Printf("path map: %s => %s\n", pm.From, pm.To)
+	}
+	if run.Format == index.FormatJournal {
+		fmt.Println("journal malformed lines:", run.Malformed)
+		fmt.Println("journal duplicate paths:", run.Duplicates)
+	}
 
 	stats := &metrics.Stats{}
 	stats.Start()
-	atomic.StoreInt64(&stats.Total, int64(len(items)))
-	atomic.StoreInt64(&stats.TotalBytes, run.TotalBytes)
 
-	bar := progress.New(run.TotalBytes, func() (p, total, ok, hash_mismatch, errc, skip, bytesHashed int64) {
+	bar := progress.New(0, func() (p, total, ok, hash_mismatch, errc, skip, bytesHashed int64) {
 		p = atomic.LoadInt64(&stats.Processed)
 		total = atomic.LoadInt64(&stats.Total)
 		ok = atomic.LoadInt64(&stats.OK)
@@ -42,24 +150,506 @@ func main() {
 	})
 	defer bar.Close()
 
-	res := verify.Verify(run.Algorithm, items, verify.Options{Workers: 2}, stats, bar)
+	if *resume && *restart {
+		panic(fmt.Errorf("-resume and -restart conflict"))
+	}
+	// A checkpoint is only kept when asked for, so plain scheduled runs
+	// write nothing next to the index.
+	done := verify.Completed{}
+	var checkpoint *verify.Checkpoint
+	if *checkpointPath != "" || *resume || *restart {
+		if *checkpointPath == "" {
+			*checkpointPath = defaultCheckpoint(reports, *indexPath)
+		}
+		mode := verify.CheckpointNew
+		switch {
+		case *resume:
+			mode = verify.CheckpointResume
+			done, err = verify.LoadCheckpoint(*checkpointPath)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println("checkpoint records:", len(done))
+		case *restart:
+			mode = verify.CheckpointOverwrite
+		}
+		checkpoint, err = verify.OpenCheckpoint(*checkpointPath, mode)
+		if errors.Is(err, verify.ErrCheckpointExists) {
+			_, _ = fmt.Fprintf(os.Stderr, "%v; pass -resume to continue that run or -restart to discard it\n", err)
+			os.Exit(2)
+		}
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("checkpoint:", *checkpointPath)
+	}
+
+	if *rolling && *historyPath == "" {
+		*historyPath = *indexPath + ".history.ndjson"
+	}
+	var history *verify.History
+	if *historyPath != "" {
+		history, err = verify.OpenHistory(*historyPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+	onRecord := func(rec verify.Record) {
+		if checkpoint != nil {
+			checkpoint.Add(rec)
+		}
+		if history != nil {
+			history.Add(rec)
+		}
+	}
+
+	readLimit := verify.NewThrottle(*rateMBps, *burstMB)
+	if *rateMBps > 0 {
+		fmt.Printf("read limit: %g MB/s, burst %g MB\n", *rateMBps, *burstMB)
+	}
+	go watchRate(os.Stdin, readLimit)
+
+	// Ctrl-C or SIGTERM stops the run; whatever was verified so far is still
+	// reported below, marked as partial.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	// Items are handed to the workers while the index is still being parsed;
+	// totals grow as they are read. Every item is validated and counted in
+	// the population; only sampled ones are verified.
+	jobs := make(chan index.FileItem)
+	loaded := make(chan struct{})
+	sendCtx, stopSending := context.WithCancel(ctx)
+	defer stopSending()
+	var loadErr error
+	var problems []index.Problem
+	var resumed, populationFiles, populationBytes int64
+	var resumedMismatches []verify.Mismatch
+	var resumedRecords []verify.Record
+	var population []index.FileItem
+	go func() {
+		defer close(loaded)
+		defer close(jobs)
+		v := index.NewValidator(run)
+		defer func() {
+			problems = v.Finish(r.Run())
+		}()
+
+		items := func(yield func(index.FileItem) bool) {
+			for fi, err := range r.All() {
+				if err != nil {
+					loadErr = err
+					return
+				}
+				v.Add(fi)
+				if fillLengths {
+					_ = index.FillLength(&fi)
+				}
+				populationFiles++
+				if history != nil {
+					population = append(population, fi)
+				}
+				if fi.Error == nil {
+					populationBytes += fi.Length
+				}
+				if sample.Percent > 0 && !sample.Keep(fi) {
+					continue
+				}
+				if !yield(fi) {
+					return
+				}
+			}
+		}
+		if sample.Count > 0 {
+			items = slices.Values(verify.SampleCount(items, sample.Count, sample.Seed))
+		}
+		if *rolling {
+			all := slices.Collect(items)
+			history.SortOldestFirst(all)
+			items = slices.Values(all)
+		}
+
+		// Once the workers stop on a budget the rest of the index is still
+		// read, so validation and the coverage population are complete.
+		sending := true
+		for fi := range items {
+			if !sending {
+				continue
+			}
+			atomic.AddInt64(&stats.Total, 1)
+			if fi.Error == nil {
+				atomic.AddInt64(&stats.TotalBytes, fi.Length)
+			}
+			bar.AddTotal(fi.Length)
+			if rec, ok := done.Lookup(fi); ok {
+				rec.Count(stats)
+				bar.AddBytes(fi.Length)
+				resumed++
+				if rec.Outcome == verify.OutcomeMismatch {
+					resumedMismatches = append(resumedMismatches, rec.Mismatch())
+				}
+				if rec.Outcome != verify.OutcomeOK {
+					resumedRecords = append(resumedRecords, rec)
+				}
+				continue
+			}
+			select {
+			case jobs <- fi:
+			case <-sendCtx.Done():
+				if ctx.Err() != nil {
+					return
+				}
+				sending = false
+			}
+		}
+	}()
+
+	res := verify.VerifyStream(ctx, run.Algorithm, jobs, verify.Options{
+		Workers:         *workers,
+		PerMount:        *perMount,
+		ResolveFallback: *fallback,
+		OnRecord:        onRecord,
+		MaxBytes:        int64(maxBytes),
+		MaxDuration:     *maxTime,
+		ReadLimit:       readLimit,
+		BufferSize:      int(readBuffer),
+		QueueDepth:      *readAhead,
+	}, stats, bar)
+
+	stopSending()
+	<-loaded
+	stop()
 	stats.Stop()
+	if checkpoint != nil {
+		// A complete run leaves nothing to resume, so the next one can
+		// start without -restart.
+		err := checkpoint.Close()
+		switch {
+		case err != nil:
+			fmt.Println("checkpoint write failed, -resume may redo some files:", err)
+		case !res.Canceled && !res.BudgetExhausted && loadErr == nil:
+			if err := os.Remove(*checkpointPath); err != nil {
+				fmt.Println("Error:", err)
+			}
+		}
+	}
+	if history != nil {
+		if err := history.Close(); err != nil {
+			fmt.Println("history write failed, some results were not recorded:", err)
+		}
+	}
+	res.Mismatches = append(resumedMismatches, res.Mismatches...)
+	res.Records = append(resumedRecords, res.Records...)
+	if res.Canceled {
+		fmt.Println()
+		fmt.Println("*** PARTIAL RUN: interrupted, results cover only the files processed so far ***")
+	}
+	fmt.Println("items count:", atomic.LoadInt64(&stats.Total))
+	if *resume {
+		fmt.Println("resumed from checkpoint:", resumed)
+	}
+	if loadErr != nil {
+		panic(loadErr)
+	}
+	if res.Canceled {
+		fmt.Println("index validation: skipped, index was not read to the end")
+	} else {
+		printProblems(problems)
+	}
 
 	metrics.Print(stats)
-	f, _ := os.Create("mismatches.txt")
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
+	var coverage *verify.Coverage
+	if sample.Enabled() || maxBytes > 0 || *maxTime > 0 || *rolling {
+		c := verify.NewCoverage(populationFiles, populationBytes, stats.Snapshot(), *corruptionRate)
+		coverage = &c
+		if res.BudgetExhausted {
+			fmt.Println("budget exhausted: stopped taking new files")
+		}
+		printCoverage(c)
+	}
+	var ages []verify.ShareAge
+	if history != nil && loadErr == nil {
+		ages = history.Ages(population)
+		printAges(ages, stats.Finished)
+	}
+	if len(res.Resolved) > 0 {
+		fmt.Println("resolved with fallback:", len(res.Resolved))
+		for _, r := range res.Resolved {
+			fmt.Printf("  %s => %s\n", r.Path, r.ResolvedPath)
+		}
+	}
+	if *mismatchesPath == "" {
+		*mismatchesPath = besideReport(reports, ".mismatches.txt")
+	}
+	if err := writeMismatches(*mismatchesPath, res, stats); err != nil {
+		fmt.Println("Error:", err)
+	}
+	if *failuresPath == "" {
+		*failuresPath = besideReport(reports, ".failures.tsv")
+	}
+	if err := writeRecords(*failuresPath, res.Records, res.Canceled); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	rep := &report.Report{
+		Index:    *indexPath,
+		Run:      report.NewRun(r.Run()),
+		Started:  stats.Started,
+		Finished: stats.Finished,
+		Partial:  res.Canceled,
+		Stats:    stats.Snapshot(),
+		Results:  res.Records,
+		Resolved: res.Resolved,
+		Coverage: coverage,
+		Ages:     ages,
+	}
+	rep.Run.Algorithm = run.Algorithm
+	if !res.Canceled {
+		for _, p := range problems {
+			rep.Problems = append(rep.Problems, p.String())
+		}
+	}
+	for _, t := range reports {
+		if err := report.WriteFile(t, rep); err != nil {
 			fmt.Println("Error:", err)
+			continue
 		}
-	}(f)
+		fmt.Printf("wrote %s report: %s\n", t.Format, t.Path)
+	}
+}
+
+// defaultCheckpoint puts the checkpoint next to the first report, or next
+// to the index when no report is written.
+func defaultCheckpoint(reports report.Targets, indexPath string) string {
+	if p := besideReport(reports, ".checkpoint.ndjson"); p != "" {
+		return p
+	}
+	return indexPath + ".checkpoint.ndjson"
+}
+
+// besideReport is the first report's path with its extension replaced by
+// suffix, or "" when no report is written.
+func besideReport(reports report.Targets, suffix string) string {
+	if len(reports) == 0 {
+		return ""
+	}
+	p := reports[0].Path
+	return strings.TrimSuffix(p, filepath.Ext(p)) + suffix
+}
+
+func printCoverage(c verify.Coverage) {
+	fmt.Println("--- coverage ---")
+	fmt.Printf("files verified: %d of %d (%.2f%%)\n", c.VerifiedFiles, c.PopulationFiles, 100*c.FileFraction)
+	fmt.Printf("bytes verified: %d of %d (%.2f%%)\n", c.VerifiedBytes, c.PopulationBytes, 100*c.ByteFraction)
+	fmt.Println("corrupt files found:", c.Corrupt)
+	fmt.Printf("corruption rate upper bound (95%%): %.4f%%\n", 100*c.UpperBound95)
+	if c.RateThreshold > 0 {
+		fmt.Printf("confidence rate < %g%%: %.2f%%\n", 100*c.RateThreshold, 100*c.Confidence)
+	}
+}
+
+// watchRate reads new read-rate limits in MB/s, one per line, until r ends.
+func watchRate(r io.Reader, t *verify.Throttle) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		mbps, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(line), "mb/s"), 64)
+		if err != nil || mbps < 0 {
+			fmt.Printf("\nread limit %q: want MB/s, 0 for unlimited\n", line)
+			continue
+		}
+		t.SetRate(mbps)
+		if mbps == 0 {
+			fmt.Println("\nread limit: unlimited")
+		} else {
+			fmt.Printf("\nread limit: %g MB/s\n", mbps)
+		}
+	}
+}
+
+// printAges prints, per share, how long the least recently verified file
+// has gone without verifying OK.
+func printAges(ages []verify.ShareAge, now time.Time) {
+	fmt.Println("--- verification age ---")
+	for _, a := range ages {
+		oldest := "never"
+		if a.NeverVerified == 0 {
+			oldest = now.Sub(a.Oldest).Round(time.Second).String()
+		}
+		fmt.Printf("%s\tfiles: %d\tnever verified: %d\toldest unverified age: %s\n", a.Share, a.Files, a.NeverVerified, oldest)
+	}
+}
+
+// byteSize is a flag.Value for sizes such as 1048576, 750M or 2T (binary units).
+type byteSize int64
+
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Set(s string) error {
+	s = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(s), "B"))
+	mult := int64(1)
+	if n := len(s); n > 0 {
+		if i := strings.IndexByte("KMGTP", s[n-1]); i >= 0 {
+			mult = 1 << (10 * (i + 1))
+			s = s[:n-1]
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	*b = byteSize(v * float64(mult))
+	return nil
+}
+
+// writeMismatches prints the mismatched files and, given a path, writes
+// them there one per line.
+func writeMismatches(path string, res *verify.Result, stats *metrics.Stats) error {
 	fmt.Println("mismatched files:", len(res.Mismatches))
 	for _, m := range res.Mismatches {
-		fmt.Println(m.Path)
-		_, err := fmt.Fprintln(f, m.Path)
+		fmt.Println(mismatchLine(m))
+	}
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if res.Canceled {
+		_, _ = fmt.Fprintf(w, "# partial run: interrupted after %d of %d files\n",
+			atomic.LoadInt64(&stats.Processed), atomic.LoadInt64(&stats.Total))
+	}
+	for _, m := range res.Mismatches {
+		_, _ = fmt.Fprintln(w, mismatchLine(m))
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func mismatchLine(m verify.Mismatch) string {
+	if m.LocalPath != "" && m.LocalPath != m.Path {
+		return m.Path + "\t" + m.LocalPath
+	}
+	return m.Path
+}
+
+// writeRecords writes every file that did not verify OK as a tab-separated
+// line, sorted by outcome then path, and prints how many there were of each.
+// With no path only the counts are printed.
+func writeRecords(path string, records []verify.Record, partial bool) error {
+	slices.SortFunc(records, func(a, b verify.Record) int {
+		if c := strings.Compare(string(a.Outcome), string(b.Outcome)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	counts := map[verify.Outcome]int{}
+	for _, rec := range records {
+		counts[rec.Outcome]++
+	}
+	fmt.Println("failed files:", len(records))
+	for _, outcome := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Printf("  %s: %d\n", outcome, counts[outcome])
+	}
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Create(path) // #nosec G304
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if partial {
+		_, _ = fmt.Fprintln(w, "# partial run")
+	}
+	_, _ = fmt.Fprintln(w, "outcome\tpath\tlocal_path\tlength\tactual_length\texpected\tcomputed\tdisagreed\terror\tduration_ms")
+	for _, rec := range records {
+		actual := ""
+		if rec.ActualLength != nil {
+			actual = strconv.FormatInt(*rec.ActualLength, 10)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			rec.Outcome, rec.Path, rec.LocalPath, rec.Length, actual, rec.Expected, rec.Computed, strings.Join(rec.Disagreed, ";"),
+			strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(rec.Error), rec.Duration.Milliseconds())
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// auditIndex lists the files under root, filtered like the indexer does, that
+// are missing from the index.
+func auditIndex(r index.Source, root string) []scan.File {
+	known := index.PathSet{}
+	var indexed int
+	for fi, err := range r.All() {
 		if err != nil {
-			fmt.Println("Error:", err)
+			panic(err)
+		}
+		known.Add(fi.FSPath())
+		indexed++
+	}
+
+	orphans, errs := scan.Orphans(root, scan.DefaultOptions(), known.Has)
+	for _, err := range errs {
+		fmt.Println("walk error:", err)
+	}
+
+	var bytes int64
+	var newest time.Time
+	for _, f := range orphans {
+		fmt.Printf("%s\t%d\t%s\n", f.Path, f.Length, f.ModTime.UTC().Format(time.RFC3339))
+		bytes += f.Length
+		if f.ModTime.After(newest) {
+			newest = f.ModTime
+		}
+	}
+	fmt.Println("audit root:", root)
+	fmt.Println("indexed files:", indexed)
+	fmt.Println("orphaned files:", len(orphans))
+	fmt.Println("orphaned bytes:", bytes)
+	if !newest.IsZero() {
+		fmt.Println("newest orphan:", newest.UTC().Format(time.RFC3339))
+	}
+	return orphans
+}
+
+func printProblems(problems []index.Problem) {
+	const maxListed = 20
+
+	if len(problems) == 0 {
+		fmt.Println("index validation: ok")
+		return
+	}
+
+	counts := map[index.ProblemKind]int{}
+	for _, p := range problems {
+		counts[p.Kind]++
+	}
+	fmt.Println("index validation problems:", len(problems))
+	for _, kind := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Printf("  %s: %d\n", kind, counts[kind])
+	}
+	for i, p := range problems {
+		if i == maxListed {
+			fmt.Printf("  ... %d more\n", len(problems)-maxListed)
+			break
 		}
+		fmt.Println(" ", p)
 	}
 }
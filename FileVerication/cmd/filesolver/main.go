@@ -1,11 +1,13 @@
 package main
 
 import (
+	"FileVerication/internal/hashalg"
 	"FileVerication/internal/verify"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -15,7 +17,7 @@ func main() {
 	)
 
 	flag.IntVar(&splits, "splits", 8, "Number of splits")
-	flag.StringVar(&algorithm, "alg", "SHA256", "Hash algorithm (SHA256, SHA1, SHA512, SHA384, MD5)")
+	flag.StringVar(&algorithm, "alg", "SHA256", "Hash algorithm ("+strings.Join(hashalg.Names(), ", ")+")")
 	flag.Parse()
 
 	paths := flag.Args()
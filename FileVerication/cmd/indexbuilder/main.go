@@ -0,0 +1,112 @@
+package main
+
+import (
+	"FileVerication/internal/build"
+	"FileVerication/internal/hashalg"
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/progress"
+	"FileVerication/internal/scan"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+func main() {
+	var opts build.Options
+	var include, exclude, update, extra string
+
+	flag.StringVar(&opts.Root, "root", "\\\\192.168.1.1\\anime", "Directory to index")
+	flag.StringVar(&opts.Out, "out", "\\\\192.168.1.1\\anime\\AnimeHashIndex.clixml", "CLIXML index to write")
+	flag.StringVar(&opts.Journal, "journal", "\\\\192.168.1.1\\anime\\AnimeHashIndex.journal.ndjson", "Append-only resume journal")
+	flag.StringVar(&opts.Algorithm, "alg", "", "Hash algorithm ("+strings.Join(hashalg.Names(), ", ")+"); default SHA256, or the -update index's")
+	flag.StringVar(&extra, "extra-alg", "", "More algorithms to hash in the same pass, comma separated, e.g. XXH3,MD5; with -update, unchanged files missing one are hashed again")
+	flag.IntVar(&opts.Workers, "workers", 8, "Number of files hashed concurrently")
+	flag.StringVar(&include, "include", strings.Join(scan.VideoExtensions, ","), "Extensions to index, comma separated (empty for all)")
+	flag.StringVar(&exclude, "exclude", strings.Join(scan.ExcludeExtensions, ","), "Extensions to always skip, comma separated")
+	flag.StringVar(&update, "update", "", "Existing index to refresh: only new or changed files are hashed (the journal is not used)")
+	flag.Parse()
+
+	if opts.Algorithm == "" && update == "" {
+		opts.Algorithm = "SHA256"
+	}
+	for _, alg := range strings.Split(extra, ",") {
+		if alg = strings.TrimSpace(alg); alg != "" && !strings.EqualFold(alg, opts.Algorithm) {
+			opts.Extra = append(opts.Extra, alg)
+		}
+	}
+	opts.Scan = scan.Options{Include: scan.ParseExtensions(include), Exclude: scan.ParseExtensions(exclude)}
+
+	if _, err := os.Stat(opts.Root); err != nil {
+		panic(fmt.Errorf("root not found: %w", err))
+	}
+	fmt.Println("root:", opts.Root)
+	fmt.Println("journal:", opts.Journal)
+	if opts.Algorithm == "" {
+		fmt.Println("algorithm: from", update, "workers:", opts.Workers)
+	} else {
+		fmt.Println("algorithm:", opts.Algorithm, "workers:", opts.Workers)
+	}
+	if len(opts.Extra) > 0 {
+		fmt.Println("extra algorithms:", strings.Join(opts.Extra, ", "))
+	}
+
+	stats := &metrics.Stats{}
+	stats.Start()
+
+	bar := progress.New(0, func() (p, total, ok, hash_mismatch, errc, skip, bytesHashed int64) {
+		p = atomic.LoadInt64(&stats.Processed)
+		total = atomic.LoadInt64(&stats.Total)
+		ok = atomic.LoadInt64(&stats.OK)
+		hash_mismatch = atomic.LoadInt64(&stats.HashMismatches)
+		errc = atomic.LoadInt64(&stats.HashErrors)
+		skip = atomic.LoadInt64(&stats.Skipped)
+		bytesHashed = atomic.LoadInt64(&stats.BytesHashed)
+		return p, total, ok, hash_mismatch, errc, skip, bytesHashed
+	})
+
+	if update != "" {
+		sum, err := build.Update(update, opts, stats, bar)
+		bar.Close()
+		stats.Stop()
+
+		fmt.Println("new:", sum.New)
+		fmt.Println("changed:", sum.Changed)
+		fmt.Println("unchanged:", sum.Unchanged)
+		fmt.Println("rehashed for -extra-alg:", sum.Rehashed)
+		fmt.Println("deleted:", sum.Deleted)
+		fmt.Println("hash errors:", sum.Errors)
+		printScanErrors(sum.ScanErrors)
+		if err != nil {
+			panic(err)
+		}
+		metrics.Print(stats)
+		fmt.Println("wrote:", opts.Out)
+		return
+	}
+
+	sum, err := build.Run(opts, stats, bar)
+	bar.Close()
+	stats.Stop()
+
+	fmt.Println("found:", sum.Found)
+	fmt.Println("resumed from journal:", sum.Resumed)
+	fmt.Println("hashed this run:", sum.Hashed, "errors:", sum.Errors)
+	printScanErrors(sum.ScanErrors)
+	if err != nil {
+		panic(err)
+	}
+	metrics.Print(stats)
+	fmt.Println("wrote:", opts.Out)
+}
+
+func printScanErrors(errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+	fmt.Println("unreadable paths skipped:", len(errs))
+	for _, e := range errs {
+		fmt.Println(" ", e)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"FileVerication/internal/index"
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		inPath    string
+		outPath   string
+		from      string
+		to        string
+		algorithm string
+		root      string
+		stat      bool
+	)
+
+	flag.StringVar(&inPath, "in", "", "Index to read")
+	flag.StringVar(&outPath, "out", "-", "File to write, or - for stdout")
+	flag.StringVar(&from, "from", "", "Input format (detected when empty)")
+	flag.StringVar(&to, "to", "", "Output format: "+formatNames()+" (taken from the -out extension when empty)")
+	flag.StringVar(&algorithm, "alg", "", "Hash algorithm to record when the input has none")
+	flag.StringVar(&root, "root", "", "Root to record when the input has none")
+	flag.BoolVar(&stat, "stat", false, "Fill missing lengths from the files on disk (for sha256sum and hashtable inputs)")
+	flag.Parse()
+
+	if inPath == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: %s -in <index> [-out file] [-from fmt] [-to fmt]\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	var inFormat index.Format
+	if from != "" {
+		f, err := index.ParseFormat(from)
+		if err != nil {
+			panic(err)
+		}
+		inFormat = f
+	}
+
+	var outFormat index.Format
+	switch {
+	case to != "":
+		f, err := index.ParseFormat(to)
+		if err != nil {
+			panic(err)
+		}
+		outFormat = f
+	case outPath != "-":
+		outFormat = index.FormatFromExt(outPath)
+	}
+	if outFormat == "" {
+		panic(fmt.Errorf("no output format: pass -to (%s)", formatNames()))
+	}
+
+	run, items, err := index.LoadFormat(inPath, inFormat)
+	if err != nil {
+		panic(err)
+	}
+	if run.Algorithm == "" {
+		run.Algorithm = algorithm
+	} else if algorithm != "" && !strings.EqualFold(algorithm, run.Algorithm) {
+		panic(fmt.Errorf("-alg %s conflicts with index algorithm %s", algorithm, run.Algorithm))
+	}
+	if run.Root == "" {
+		run.Root = root
+	}
+
+	if stat {
+		if failed := index.FillLengths(items); failed > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "could not stat %d files\n", failed)
+		}
+	}
+
+	if outPath == "-" {
+		w := bufio.NewWriter(os.Stdout)
+		if err := index.Write(w, outFormat, run, items); err != nil {
+			panic(err)
+		}
+		if err := w.Flush(); err != nil {
+			panic(err)
+		}
+	} else if err := index.SaveFormat(outPath, outFormat, run, items); err != nil {
+		panic(err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "%s -> %s: %d items (%s -> %s)\n", inPath, outPath, len(items), run.Format, outFormat)
+	if run.Malformed > 0 || run.Duplicates > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "skipped %d malformed lines, %d duplicate paths\n", run.Malformed, run.Duplicates)
+	}
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(index.Formats))
+	for _, f := range index.Formats {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"FileVerication/internal/index"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type report struct {
+	Old     string                   `json:"old"`
+	New     string                   `json:"new"`
+	Counts  map[index.ChangeKind]int `json:"counts"`
+	Changes []index.Change           `json:"changes"`
+}
+
+func main() {
+	jsonPath := flag.String("json", "", "also write the diff as JSON to this file, or - for stdout instead of text")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [-json out.json] <old index> <new index>\n", os.Args[0])
+		os.Exit(2)
+	}
+	oldPath, newPath := flag.Arg(0), flag.Arg(1)
+
+	oldRun, oldItems, err := index.Load(oldPath)
+	if err != nil {
+		panic(err)
+	}
+	newRun, newItems, err := index.Load(newPath)
+	if err != nil {
+		panic(err)
+	}
+	if oldRun.Algorithm != "" && newRun.Algorithm != "" && !strings.EqualFold(oldRun.Algorithm, newRun.Algorithm) {
+		panic(fmt.Errorf("cannot compare %s hashes with %s hashes", oldRun.Algorithm, newRun.Algorithm))
+	}
+
+	changes := index.Diff(oldRun, oldItems, newRun, newItems)
+	rep := report{Old: oldPath, New: newPath, Counts: map[index.ChangeKind]int{}, Changes: changes}
+	for _, c := range changes {
+		rep.Counts[c.Kind]++
+	}
+
+	if *jsonPath != "" {
+		out := os.Stdout
+		if *jsonPath != "-" {
+			f, err := os.Create(*jsonPath) // #nosec G304
+			if err != nil {
+				panic(err)
+			}
+			defer func(f *os.File) {
+				_ = f.Close()
+			}(f)
+			out = f
+		}
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			panic(err)
+		}
+		if *jsonPath == "-" {
+			return
+		}
+	}
+
+	fmt.Printf("old: %s (%d items)\n", oldPath, len(oldItems))
+	fmt.Printf("new: %s (%d items)\n", newPath, len(newItems))
+	for _, kind := range index.ChangeKinds {
+		fmt.Printf("  %s: %d\n", kind, rep.Counts[kind])
+	}
+
+	for _, kind := range index.ChangeKinds {
+		if rep.Counts[kind] == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", kind)
+		for _, c := range changes {
+			if c.Kind != kind {
+				continue
+			}
+			switch kind {
+			case index.ChangeAdded:
+				fmt.Printf("  + %s\n", c.Path)
+			case index.ChangeRemoved:
+				fmt.Printf("  - %s\n", c.OldPath)
+			case index.ChangeMoved:
+				fmt.Printf("  %s -> %s\n", c.OldPath, c.Path)
+			default:
+				fmt.Printf("  %s (%s %d -> %s %d)\n", c.Path, c.OldHash, c.OldLength, c.Hash, c.Length)
+			}
+		}
+	}
+}
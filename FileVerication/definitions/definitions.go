@@ -2,29 +2,173 @@ package definitions
 
 import (
 	"encoding/xml"
+	"fmt"
+	"io"
 	"strconv"
 )
 
+const (
+	Namespace     = "http://schemas.microsoft.com/powershell/2004/04"
+	SchemaVersion = "1.1.0.1"
+)
+
 type Objs struct {
 	XMLName xml.Name `xml:"Objs"`
+	Version string   `xml:"Version,attr,omitempty"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
 	Objects []Obj    `xml:"Obj"`
 }
 
+func (o *Objs) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*o = Objs{
+		XMLName: start.Name,
+		Version: attr(start, "Version"),
+		Xmlns:   attr(start, "xmlns"),
+	}
+	items, err := decodeItems(d)
+	o.Objects = items
+	return err
+}
+
+// Obj is a complex object. Elements of a list, and top-level objects, may
+// also be primitives or back-references; those are carried as an Obj whose
+// Tag names the element ("S", "Ref", ...) and whose Value holds the decoded
+// primitive. Tag is empty for a real <Obj>.
 type Obj struct {
-	RefID int     `xml:"RefId,attr"`
-	Name  string  `xml:"N,attr,omitempty"`
-	MS    *Member `xml:"MS"`
-	LST   *List   `xml:"LST"`
-	DCT   *Dict   `xml:"DCT"`
+	Name     string       `xml:"N,attr,omitempty"`
+	RefID    int          `xml:"RefId,attr"`
+	TN       *TypeNames   `xml:"TN"`
+	TNRef    *TypeNameRef `xml:"TNRef"`
+	ToString string       `xml:"ToString,omitempty"`
+	Props    *Member      `xml:"Props"`
+	MS       *Member      `xml:"MS"`
+	LST      *List        `xml:"LST"`
+	IE       *List        `xml:"IE"`
+	STK      *List        `xml:"STK"`
+	QUE      *List        `xml:"QUE"`
+	DCT      *Dict        `xml:"DCT"`
+	Tag      string       `xml:"-"`
+	Value    any          `xml:"-"`
+}
+
+func (o *Obj) IsRef() bool {
+	return o.Tag == "Ref"
+}
+
+func (o *Obj) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*o = Obj{Name: attr(start, "N")}
+	if err := refIDAttr(start, &o.RefID); err != nil {
+		return err
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "TN":
+				o.TN = &TypeNames{}
+				err = d.DecodeElement(o.TN, &t)
+			case "TNRef":
+				o.TNRef = &TypeNameRef{}
+				err = d.DecodeElement(o.TNRef, &t)
+			case "ToString":
+				var s string
+				err = d.DecodeElement(&s, &t)
+				o.ToString = DecodeString(s)
+			case "Props":
+				o.Props = &Member{}
+				err = d.DecodeElement(o.Props, &t)
+			case "MS":
+				o.MS = &Member{}
+				err = d.DecodeElement(o.MS, &t)
+			case "LST":
+				o.LST = &List{}
+				err = d.DecodeElement(o.LST, &t)
+			case "IE":
+				o.IE = &List{}
+				err = d.DecodeElement(o.IE, &t)
+			case "STK":
+				o.STK = &List{}
+				err = d.DecodeElement(o.STK, &t)
+			case "QUE":
+				o.QUE = &List{}
+				err = d.DecodeElement(o.QUE, &t)
+			case "DCT":
+				o.DCT = &Dict{}
+				err = d.DecodeElement(o.DCT, &t)
+			default:
+				// Enums and other wrapped primitives carry their value as an
+				// unnamed primitive child.
+				if IsPrimitive(t.Name.Local) {
+					o.Value, err = decodePrimitiveElement(d, t)
+				} else {
+					err = d.Skip()
+				}
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
+func (o Obj) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	switch {
+	case o.Tag == "":
+		type plain Obj
+		return e.EncodeElement(plain(o), start)
+	case o.IsRef():
+		start.Name.Local = "Ref"
+		start.Attr = nil
+		if o.Name != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "N"}, Value: o.Name})
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "RefId"}, Value: strconv.Itoa(o.RefID)})
+		return e.EncodeElement("", start)
+	default:
+		tag, text := EncodePrimitive(o.Value)
+		start.Name.Local = tag
+		start.Attr = nil
+		if o.Name != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "N"}, Value: o.Name})
+		}
+		return e.EncodeElement(text, start)
+	}
+}
+
+type TypeNames struct {
+	RefID int      `xml:"RefId,attr"`
+	Names []string `xml:"T"`
+}
+
+type TypeNameRef struct {
+	RefID int `xml:"RefId,attr"`
+}
+
+// Member holds the children of <MS> or <Props>. Strings, Int32s and Algorithm
+// are kept for the index reader; Props has every primitive member, typed by
+// DecodePrimitive, and Objs every complex member or back-reference.
 type Member struct {
 	Strings   []NamedString `xml:"S"`
 	Int32s    []NamedInt32  `xml:"I32"`
 	Objs      []Obj         `xml:"Obj"`
+	Props     []Property    `xml:"-"`
 	Algorithm string        `xml:"-"`
 }
 
+type Property struct {
+	Name  string
+	Tag   string
+	Value any
+}
+
 func (m *Member) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	*m = Member{}
 
@@ -37,31 +181,39 @@ func (m *Member) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 		switch t := tok.(type) {
 		case xml.StartElement:
 			switch t.Name.Local {
-			case "S":
-				var ns NamedString
-				if err := d.DecodeElement(&ns, &t); err != nil {
-					return err
-				}
-				m.Strings = append(m.Strings, ns)
-				if ns.Name == "algorithm" {
-					m.Algorithm = ns.Value
-				}
-			case "I32":
-				var ni NamedInt32
-				if err := d.DecodeElement(&ni, &t); err != nil {
-					return err
-				}
-				m.Int32s = append(m.Int32s, ni)
-			case "Obj":
-				var o Obj
-				if err := d.DecodeElement(&o, &t); err != nil {
+			case "Obj", "Ref":
+				o, err := decodeItem(d, t)
+				if err != nil {
 					return err
 				}
 				m.Objs = append(m.Objs, o)
 			default:
-				if err := d.Skip(); err != nil {
+				if !IsPrimitive(t.Name.Local) {
+					if err := d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+
+				name := attr(t, "N")
+				v, err := decodePrimitiveElement(d, t)
+				if err != nil {
 					return err
 				}
+				m.Props = append(m.Props, Property{Name: name, Tag: t.Name.Local, Value: v})
+
+				switch val := v.(type) {
+				case string:
+					if t.Name.Local != "S" {
+						continue
+					}
+					m.Strings = append(m.Strings, NamedString{Name: name, Value: val})
+					if name == "algorithm" {
+						m.Algorithm = val
+					}
+				case int32:
+					m.Int32s = append(m.Int32s, NamedInt32{Name: name, Value: val})
+				}
 			}
 		case xml.EndElement:
 			if t.Name.Local == start.Name.Local {
@@ -71,20 +223,48 @@ func (m *Member) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	}
 }
 
+// Get returns the named member: a primitive value, or a *Obj for complex
+// members and back-references.
+func (m *Member) Get(name string) (any, bool) {
+	for _, p := range m.Props {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	for i := range m.Objs {
+		if m.Objs[i].Name == name {
+			return &m.Objs[i], true
+		}
+	}
+	return nil, false
+}
+
 type NamedString struct {
 	Name  string `xml:"N,attr"`
 	Value string `xml:",chardata"`
 }
 
+func (ns NamedString) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{{Name: xml.Name{Local: "N"}, Value: ns.Name}}
+	return e.EncodeElement(EncodeString(ns.Value), start)
+}
+
 type NamedInt32 struct {
 	Name  string `xml:"N,attr"`
 	Value int32  `xml:",chardata"`
 }
 
+// List is the content of <LST>, <IE>, <STK> or <QUE>, in document order.
 type List struct {
 	Items []Obj `xml:"Obj"`
 }
 
+func (l *List) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	items, err := decodeItems(d)
+	l.Items = items
+	return err
+}
+
 type Dict struct {
 	Entries []En `xml:"En"`
 }
@@ -93,25 +273,77 @@ type En struct {
 	Fields []Field `xml:",any"`
 }
 
+// Field is the Key or Value of a dictionary entry. Primitive values keep their
+// raw text; complex values and back-references are decoded into Obj.
 type Field struct {
 	XMLName xml.Name
 	N       string `xml:"N,attr"`
 	Text    string `xml:",chardata"`
+	Obj     *Obj   `xml:"-"`
 }
 
+func (f *Field) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = Field{XMLName: start.Name, N: attr(start, "N")}
+
+	switch start.Name.Local {
+	case "Obj", "Ref":
+		o, err := decodeItem(d, start)
+		if err != nil {
+			return err
+		}
+		f.Obj = &o
+		return nil
+	}
+	return d.DecodeElement(&f.Text, &start)
+}
+
+func (f Field) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: f.XMLName.Local},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "N"}, Value: f.N}},
+	}
+	if f.Obj != nil {
+		o := *f.Obj
+		o.Name = f.N
+		return e.EncodeElement(o, xml.StartElement{Name: xml.Name{Local: "Obj"}})
+	}
+	if f.XMLName.Local == "S" {
+		return e.EncodeElement(EncodeString(f.Text), start)
+	}
+	return e.EncodeElement(f.Text, start)
+}
+
+// NewEntry builds a Key/Value dictionary entry, picking the CLIXML element for
+// val the same way Export-Clixml does. It is the inverse of KeyValue.
+func NewEntry(key string, val any) En {
+	value := Field{N: "Value"}
+	if o, ok := val.(*Obj); ok {
+		value.XMLName.Local = "Obj"
+		value.Obj = o
+	} else {
+		value.XMLName.Local, value.Text = EncodePrimitive(val)
+	}
+
+	return En{Fields: []Field{
+		{XMLName: xml.Name{Local: "S"}, N: "Key", Text: key},
+		value,
+	}}
+}
+
+// KeyValue decodes a dictionary entry. Primitive values are typed as by
+// DecodePrimitive, complex values are returned as *Obj and back-references
+// as Ref.
 func (e En) KeyValue() (key string, val any, ok bool, err error) {
 	var keyFound, valFound bool
-	var valTag string
-	var valText string
+	var valField Field
 
 	for _, f := range e.Fields {
 		switch f.N {
 		case "Key":
-			key = f.Text
+			key = DecodeString(f.Text)
 			keyFound = true
 		case "Value":
-			valTag = f.XMLName.Local
-			valText = f.Text
+			valField = f
 			valFound = true
 		}
 	}
@@ -120,28 +352,123 @@ func (e En) KeyValue() (key string, val any, ok bool, err error) {
 		return "", nil, false, nil
 	}
 
-	switch valTag {
-	case "S":
-		val = valText
-	case "B":
-		val = valText == "true" || valText == "True" || valText == "1"
-	case "I64":
-		n, parseErr := strconv.ParseInt(valText, 10, 64)
-		if parseErr != nil {
-			return "", nil, false, parseErr
-		}
-		val = n
-	case "I32":
-		n, parseErr := strconv.ParseInt(valText, 10, 32)
-		if parseErr != nil {
-			return "", nil, false, parseErr
+	switch {
+	case valField.Obj != nil && valField.Obj.IsRef():
+		val = Ref{RefID: valField.Obj.RefID}
+	case valField.Obj != nil:
+		val = valField.Obj
+	case IsPrimitive(valField.XMLName.Local):
+		val, err = DecodePrimitive(valField.XMLName.Local, valField.Text)
+		if err != nil {
+			return "", nil, false, err
 		}
-		val = int32(n)
-	case "Nil":
-		val = nil
 	default:
-		val = valText
+		val = valField.Text
 	}
 
 	return key, val, true, nil
 }
+
+// Encode writes doc as a CLIXML document with the same header and indentation
+// Export-Clixml uses.
+func (o Objs) Encode(w io.Writer) error {
+	if o.Version == "" {
+		o.Version = SchemaVersion
+	}
+	if o.Xmlns == "" {
+		o.Xmlns = Namespace
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(o); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// decodeItems reads list elements up to the end of the enclosing element.
+func decodeItems(d *xml.Decoder) ([]Obj, error) {
+	var items []Obj
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return items, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "Obj" && t.Name.Local != "Ref" && !IsPrimitive(t.Name.Local) {
+				if err := d.Skip(); err != nil {
+					return items, err
+				}
+				continue
+			}
+			o, err := decodeItem(d, t)
+			if err != nil {
+				return items, err
+			}
+			items = append(items, o)
+		case xml.EndElement:
+			return items, nil
+		}
+	}
+}
+
+// decodeItem decodes an <Obj>, a <Ref> or a primitive element into an Obj.
+func decodeItem(d *xml.Decoder, start xml.StartElement) (Obj, error) {
+	switch start.Name.Local {
+	case "Obj":
+		var o Obj
+		err := d.DecodeElement(&o, &start)
+		return o, err
+	case "Ref":
+		o := Obj{Name: attr(start, "N"), Tag: "Ref"}
+		if err := refIDAttr(start, &o.RefID); err != nil {
+			return o, err
+		}
+		return o, d.Skip()
+	default:
+		o := Obj{Name: attr(start, "N"), Tag: start.Name.Local}
+		v, err := decodePrimitiveElement(d, start)
+		o.Value = v
+		return o, err
+	}
+}
+
+func decodePrimitiveElement(d *xml.Decoder, start xml.StartElement) (any, error) {
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	return DecodePrimitive(start.Name.Local, text)
+}
+
+func attr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func refIDAttr(se xml.StartElement, dst *int) error {
+	s := attr(se, "RefId")
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("clixml: invalid RefId %q on <%s>", s, se.Name.Local)
+	}
+	*dst = n
+	return nil
+}
@@ -0,0 +1,177 @@
+package definitions
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodePrimitive_TableDriven(t *testing.T) {
+	guid, _ := ParseGUID("7d3f2c1a-0b4e-4f6a-9c8d-112233445566")
+	uri, _ := url.Parse("https://anidb.net/anime/1")
+
+	tests := []struct {
+		tag     string
+		text    string
+		want    any
+		wantErr bool
+	}{
+		{"S", "a_x000A_b_x005F_x", "a\nb_x", false},
+		{"C", "97", Char('a'), false},
+		{"B", "true", true, false},
+		{"DT", "2026-02-16T23:09:08.4209857Z", time.Date(2026, 2, 16, 23, 9, 8, 420985700, time.UTC), false},
+		{"DT", "2026-02-16T23:09:08", time.Date(2026, 2, 16, 23, 9, 8, 0, time.UTC), false},
+		{"TS", "PT1H2M3.5S", time.Hour + 2*time.Minute + 3500*time.Millisecond, false},
+		{"TS", "-P1DT12H", -36 * time.Hour, false},
+		{"TS", "1:00:00", nil, true},
+		{"By", "255", uint8(255), false},
+		{"SB", "-5", int8(-5), false},
+		{"U16", "65535", uint16(65535), false},
+		{"I16", "-2", int16(-2), false},
+		{"U32", "7", uint32(7), false},
+		{"I32", "-7", int32(-7), false},
+		{"U64", "18446744073709551615", uint64(18446744073709551615), false},
+		{"I64", "123456789012", int64(123456789012), false},
+		{"I64", "abc", nil, true},
+		{"Sg", "1.5", float32(1.5), false},
+		{"Db", "2.25", 2.25, false},
+		{"D", "79228162514264337593543950335", Decimal("79228162514264337593543950335"), false},
+		{"BA", "AQID", []byte{1, 2, 3}, false},
+		{"G", "7d3f2c1a-0b4e-4f6a-9c8d-112233445566", guid, false},
+		{"URI", "https://anidb.net/anime/1", uri, false},
+		{"Version", "7.4.1", Version("7.4.1"), false},
+		{"Nil", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.tag+" "+tt.text, func(t *testing.T) {
+			got, err := DecodePrimitive(tt.tag, tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("mismatch:\n got: %#v\nwant: %#v", got, tt.want)
+			}
+
+			tag, text := EncodePrimitive(got)
+			if tag != tt.tag {
+				t.Fatalf("EncodePrimitive tag: got %q want %q", tag, tt.tag)
+			}
+			again, err := DecodePrimitive(tag, text)
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Fatalf("re-decode of %q mismatch: got %#v (%v) want %#v", text, again, err, got)
+			}
+		})
+	}
+}
+
+func TestObjs_RefsAndTypeNames(t *testing.T) {
+	const doc = `<Objs Version="1.1.0.1" xmlns="http://schemas.microsoft.com/powershell/2004/04">
+  <Obj RefId="0">
+    <TN RefId="0">
+      <T>System.Management.Automation.PSCustomObject</T>
+      <T>System.Object</T>
+    </TN>
+    <MS>
+      <DT N="createdUtc">2026-02-16T23:09:08.4209857Z</DT>
+      <U64 N="bytes">42</U64>
+      <Obj N="items" RefId="1">
+        <TN RefId="1"><T>System.Object[]</T></TN>
+        <LST>
+          <Obj RefId="2">
+            <TN RefId="2"><T>System.Collections.Hashtable</T></TN>
+            <DCT>
+              <En><S N="Key">when</S><DT N="Value">2026-01-01T00:00:00Z</DT></En>
+              <En><S N="Key">nested</S><Obj N="Value" RefId="3"><TNRef RefId="0" /><MS><S N="x">y</S></MS></Obj></En>
+            </DCT>
+          </Obj>
+          <Ref RefId="2" />
+          <S>plain</S>
+          <Obj RefId="4">
+            <TNRef RefId="2" />
+            <DCT>
+              <En><S N="Key">again</S><Ref N="Value" RefId="3" /></En>
+            </DCT>
+          </Obj>
+        </LST>
+      </Obj>
+      <Obj N="level" RefId="5">
+        <TN RefId="3"><T>System.ConsoleColor</T><T>System.Enum</T></TN>
+        <ToString>Red</ToString>
+        <I32>12</I32>
+      </Obj>
+    </MS>
+  </Obj>
+</Objs>`
+
+	var objs Objs
+	if err := xml.Unmarshal([]byte(doc), &objs); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	refs := objs.Refs()
+	ms := objs.Objects[0].MS
+
+	if v, _ := ms.Get("createdUtc"); v != time.Date(2026, 2, 16, 23, 9, 8, 420985700, time.UTC) {
+		t.Fatalf("createdUtc: got %#v", v)
+	}
+	if v, _ := ms.Get("bytes"); v != uint64(42) {
+		t.Fatalf("bytes: got %#v", v)
+	}
+	if v, _ := ms.Get("level"); v.(*Obj).Value != int32(12) || v.(*Obj).ToString != "Red" {
+		t.Fatalf("enum member: got %#v", v)
+	}
+
+	v, _ := ms.Get("items")
+	items := v.(*Obj).LST.Items
+	if len(items) != 4 {
+		t.Fatalf("items length: got %d want 4", len(items))
+	}
+	if !items[1].IsRef() || refs.Resolve(&items[1]) != refs.Resolve(&items[0]) {
+		t.Fatalf("Ref item did not resolve to RefId 2: %#v", items[1])
+	}
+	if items[2].Tag != "S" || items[2].Value != "plain" {
+		t.Fatalf("primitive item: got %#v", items[2])
+	}
+	if got := refs.TypeNames(&items[3]); !reflect.DeepEqual(got, []string{"System.Collections.Hashtable"}) {
+		t.Fatalf("TNRef type names: got %v", got)
+	}
+
+	_, when, _, err := items[0].DCT.Entries[0].KeyValue()
+	if err != nil || when != time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("DT entry: got %#v (%v)", when, err)
+	}
+	_, nested, _, _ := items[0].DCT.Entries[1].KeyValue()
+	if names := refs.TypeNames(nested.(*Obj)); len(names) == 0 || names[0] != "System.Management.Automation.PSCustomObject" {
+		t.Fatalf("nested TNRef: got %v", names)
+	}
+	_, again, _, _ := items[3].DCT.Entries[0].KeyValue()
+	ref, ok := again.(Ref)
+	if !ok {
+		t.Fatalf("Ref entry: got %#v", again)
+	}
+	if o, ok := refs.Lookup(ref); !ok || o != nested.(*Obj) {
+		t.Fatalf("Ref entry did not resolve to the nested object")
+	}
+
+	var buf bytes.Buffer
+	if err := objs.Encode(&buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var back Objs
+	if err := xml.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatalf("re-Unmarshal: %v\n%s", err, buf.String())
+	}
+	if got := back.Objects[0].MS.Objs[0].LST.Items; len(got) != 4 || !got[1].IsRef() || got[2].Value != "plain" {
+		t.Fatalf("re-encoded list mismatch: %#v", got)
+	}
+}
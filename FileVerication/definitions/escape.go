@@ -0,0 +1,73 @@
+package definitions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// EncodeString applies the _xHHHH_ escaping PowerShell's serializer uses for
+// <S> content: control characters, characters XML cannot carry and any "_x"
+// that would otherwise be read back as an escape.
+func EncodeString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i, r := range s {
+		switch {
+		case r == '_' && i+1 < len(s) && s[i+1] == 'x':
+			b.WriteString("_x005F_")
+		case r < 0x20, r == 0xFFFE, r == 0xFFFF:
+			fmt.Fprintf(&b, "_x%04X_", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DecodeString reverses EncodeString, including surrogate pairs that
+// PowerShell writes as two consecutive escapes.
+func DecodeString(s string) string {
+	if !strings.Contains(s, "_x") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		u, ok := escapeAt(s, i)
+		if !ok {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		i += 7
+
+		if utf16.IsSurrogate(rune(u)) {
+			if lo, ok := escapeAt(s, i); ok {
+				if r := utf16.DecodeRune(rune(u), rune(lo)); r != unicode.ReplacementChar {
+					b.WriteRune(r)
+					i += 7
+					continue
+				}
+			}
+		}
+		b.WriteRune(rune(u))
+	}
+	return b.String()
+}
+
+func escapeAt(s string, i int) (uint16, bool) {
+	if i+7 > len(s) || s[i] != '_' || s[i+1] != 'x' || s[i+6] != '_' {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(n), true
+}
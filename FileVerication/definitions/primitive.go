@@ -0,0 +1,305 @@
+package definitions
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types for primitives that have no exact Go counterpart. They keep the
+// serialized text so nothing is lost on the way through.
+type (
+	Char        rune
+	Decimal     string
+	Version     string
+	XMLDocument string
+	ScriptBlock string
+	SecureStr   string
+)
+
+// GUID holds the 16 bytes of a <G> element in the order they are written.
+type GUID [16]byte
+
+func ParseGUID(s string) (GUID, error) {
+	var g GUID
+	h := strings.ReplaceAll(strings.Trim(strings.TrimSpace(s), "{}"), "-", "")
+	if len(h) != 32 {
+		return g, fmt.Errorf("clixml: invalid guid %q", s)
+	}
+	if _, err := hex.Decode(g[:], []byte(h)); err != nil {
+		return g, fmt.Errorf("clixml: invalid guid %q: %w", s, err)
+	}
+	return g, nil
+}
+
+func (g GUID) String() string {
+	h := hex.EncodeToString(g[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// Ref is a <Ref RefId="n"/> back-reference to an object serialized earlier
+// in the same document. Refs.Resolve maps it to that object.
+type Ref struct {
+	RefID int
+}
+
+// IsPrimitive reports whether tag is one of the primitive CLIXML elements
+// DecodePrimitive understands.
+func IsPrimitive(tag string) bool {
+	switch tag {
+	case "S", "C", "B", "DT", "TS", "By", "SB", "U16", "I16", "U32", "I32", "U64", "I64",
+		"Sg", "Db", "D", "BA", "G", "URI", "Version", "XD", "SBK", "SS", "Nil":
+		return true
+	}
+	return false
+}
+
+// DecodePrimitive converts the text of a primitive element to its Go value:
+//
+//	S string        C Char          B bool          DT time.Time
+//	TS time.Duration                By uint8        SB int8
+//	U16 uint16      I16 int16       U32 uint32      I32 int32
+//	U64 uint64      I64 int64       Sg float32      Db float64
+//	D Decimal       BA []byte       G GUID          URI *url.URL
+//	Version Version XD XMLDocument  SBK ScriptBlock SS SecureStr
+//	Nil nil
+func DecodePrimitive(tag, text string) (any, error) {
+	switch tag {
+	case "S":
+		return DecodeString(text), nil
+	case "C":
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 16)
+		return Char(n), err
+	case "B":
+		b := strings.TrimSpace(text)
+		return b == "true" || b == "True" || b == "1", nil
+	case "DT":
+		return ParseDateTime(text)
+	case "TS":
+		return ParseDuration(text)
+	case "By":
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 8)
+		return uint8(n), err
+	case "SB":
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 8)
+		return int8(n), err
+	case "U16":
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 16)
+		return uint16(n), err
+	case "I16":
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 16)
+		return int16(n), err
+	case "U32":
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 32)
+		return uint32(n), err
+	case "I32":
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32)
+		return int32(n), err
+	case "U64":
+		return strconv.ParseUint(strings.TrimSpace(text), 10, 64)
+	case "I64":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "Sg":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 32)
+		return float32(f), err
+	case "Db":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "D":
+		return Decimal(strings.TrimSpace(text)), nil
+	case "BA":
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	case "G":
+		return ParseGUID(text)
+	case "URI":
+		return url.Parse(DecodeString(text))
+	case "Version":
+		return Version(strings.TrimSpace(text)), nil
+	case "XD":
+		return XMLDocument(DecodeString(text)), nil
+	case "SBK":
+		return ScriptBlock(DecodeString(text)), nil
+	case "SS":
+		return SecureStr(strings.TrimSpace(text)), nil
+	case "Nil":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("clixml: %q is not a primitive element", tag)
+}
+
+// ParseDateTime parses a <DT> value. .NET writes local and UTC times with an
+// offset and DateTimeKind.Unspecified without one; the latter is read as UTC.
+func ParseDateTime(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", text)
+}
+
+// ParseDuration parses the xs:duration form .NET uses for <TS>, such as
+// "PT1H2M3.5S" or "-P1DT12H". Years and months, which TimeSpan never
+// produces, are taken as 365 and 30 days.
+func ParseDuration(text string) (time.Duration, error) {
+	s := strings.TrimSpace(text)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if !strings.HasPrefix(s, "P") || len(s) < 2 {
+		return 0, fmt.Errorf("clixml: invalid duration %q", text)
+	}
+	s = s[1:]
+
+	const day = 24 * time.Hour
+	var total float64
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime = true
+			s = s[1:]
+			continue
+		}
+		i := strings.IndexAny(s, "YMDHS")
+		if i <= 0 {
+			return 0, fmt.Errorf("clixml: invalid duration %q", text)
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("clixml: invalid duration %q: %w", text, err)
+		}
+
+		var unit time.Duration
+		switch {
+		case s[i] == 'Y' && !inTime:
+			unit = 365 * day
+		case s[i] == 'M' && !inTime:
+			unit = 30 * day
+		case s[i] == 'D' && !inTime:
+			unit = day
+		case s[i] == 'H' && inTime:
+			unit = time.Hour
+		case s[i] == 'M' && inTime:
+			unit = time.Minute
+		case s[i] == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("clixml: invalid duration %q", text)
+		}
+		total += n * float64(unit)
+		s = s[i+1:]
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("clixml: duration %q out of range", text)
+	}
+	d := time.Duration(math.Round(total))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+// EncodePrimitive is the inverse of DecodePrimitive: it picks the element
+// Export-Clixml would write for v and returns it with the element text.
+// Values of other types are written as strings.
+func EncodePrimitive(v any) (tag, text string) {
+	switch x := v.(type) {
+	case nil:
+		return "Nil", ""
+	case *string:
+		if x == nil {
+			return "Nil", ""
+		}
+		return "S", *x
+	case string:
+		return "S", x
+	case bool:
+		return "B", strconv.FormatBool(x)
+	case Char:
+		return "C", strconv.FormatUint(uint64(x), 10)
+	case time.Time:
+		return "DT", x.Format("2006-01-02T15:04:05.0000000Z07:00")
+	case time.Duration:
+		return "TS", FormatDuration(x)
+	case uint8:
+		return "By", strconv.FormatUint(uint64(x), 10)
+	case int8:
+		return "SB", strconv.FormatInt(int64(x), 10)
+	case uint16:
+		return "U16", strconv.FormatUint(uint64(x), 10)
+	case int16:
+		return "I16", strconv.FormatInt(int64(x), 10)
+	case uint32:
+		return "U32", strconv.FormatUint(uint64(x), 10)
+	case int32:
+		return "I32", strconv.FormatInt(int64(x), 10)
+	case int:
+		return "I32", strconv.Itoa(x)
+	case uint64:
+		return "U64", strconv.FormatUint(x, 10)
+	case int64:
+		return "I64", strconv.FormatInt(x, 10)
+	case float32:
+		return "Sg", strconv.FormatFloat(float64(x), 'G', -1, 32)
+	case float64:
+		return "Db", strconv.FormatFloat(x, 'G', -1, 64)
+	case Decimal:
+		return "D", string(x)
+	case []byte:
+		return "BA", base64.StdEncoding.EncodeToString(x)
+	case GUID:
+		return "G", x.String()
+	case *url.URL:
+		return "URI", x.String()
+	case Version:
+		return "Version", string(x)
+	case XMLDocument:
+		return "XD", string(x)
+	case ScriptBlock:
+		return "SBK", string(x)
+	case SecureStr:
+		return "SS", string(x)
+	default:
+		return "S", fmt.Sprint(v)
+	}
+}
+
+// FormatDuration writes d in the xs:duration form ParseDuration reads.
+func FormatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	const day = 24 * time.Hour
+	if days := d / day; days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * day
+	}
+	if d == 0 {
+		if b.Len() <= 2 {
+			b.WriteString("T0S")
+		}
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		b.WriteByte('S')
+	}
+	return b.String()
+}
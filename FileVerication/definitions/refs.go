@@ -0,0 +1,87 @@
+package definitions
+
+// Refs indexes the objects and type names of a document by RefId, so <Ref>
+// and <TNRef> elements can be resolved. Object and type-name RefIds are
+// separate sequences.
+type Refs struct {
+	objs  map[int]*Obj
+	types map[int][]string
+}
+
+func NewRefs() *Refs {
+	return &Refs{objs: map[int]*Obj{}, types: map[int][]string{}}
+}
+
+// Refs builds the reference table for the whole document.
+func (o *Objs) Refs() *Refs {
+	r := NewRefs()
+	for i := range o.Objects {
+		r.Add(&o.Objects[i])
+	}
+	return r
+}
+
+// Add registers o and every object nested inside it. o must not be moved
+// afterwards, as the table keeps pointers into it.
+func (r *Refs) Add(o *Obj) {
+	if o == nil || o.Tag != "" {
+		return
+	}
+	r.objs[o.RefID] = o
+	if o.TN != nil {
+		r.types[o.TN.RefID] = o.TN.Names
+	}
+
+	for _, m := range []*Member{o.Props, o.MS} {
+		if m == nil {
+			continue
+		}
+		for i := range m.Objs {
+			r.Add(&m.Objs[i])
+		}
+	}
+	for _, l := range []*List{o.LST, o.IE, o.STK, o.QUE} {
+		if l == nil {
+			continue
+		}
+		for i := range l.Items {
+			r.Add(&l.Items[i])
+		}
+	}
+	if o.DCT != nil {
+		for _, en := range o.DCT.Entries {
+			for _, f := range en.Fields {
+				r.Add(f.Obj)
+			}
+		}
+	}
+}
+
+// Resolve follows a back-reference. Objects that are not references are
+// returned unchanged; unknown references resolve to nil.
+func (r *Refs) Resolve(o *Obj) *Obj {
+	if o == nil || !o.IsRef() {
+		return o
+	}
+	return r.objs[o.RefID]
+}
+
+// Lookup returns the object serialized with the given RefId.
+func (r *Refs) Lookup(ref Ref) (*Obj, bool) {
+	o, ok := r.objs[ref.RefID]
+	return o, ok
+}
+
+// TypeNames returns the type hierarchy of o, following <TNRef> and <Ref>.
+func (r *Refs) TypeNames(o *Obj) []string {
+	o = r.Resolve(o)
+	switch {
+	case o == nil:
+		return nil
+	case o.TN != nil:
+		return o.TN.Names
+	case o.TNRef != nil:
+		return r.types[o.TNRef.RefID]
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package build
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/progress"
+	"FileVerication/internal/scan"
+	"FileVerication/internal/verify"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Options struct {
+	Root      string
+	Journal   string
+	Out       string
+	Algorithm string
+	// Extra algorithms are hashed in the same read and stored in
+	// FileItem.Hashes.
+	Extra   []string
+	Workers int
+	Scan    scan.Options
+}
+
+type Summary struct {
+	Found      int
+	Resumed    int
+	Hashed     int
+	Errors     int
+	ScanErrors []error
+}
+
+// Run does what Build-VideoHashIndex-Parallel.ps1 does: walk Root, hash every
+// file the journal does not list yet, append each result to the journal as
+// it completes, and finally write the CLIXML index for the whole journal to
+// Out. Interrupting Run and calling it again resumes where it stopped.
+func Run(opts Options, stats *metrics.Stats, bar *progress.Bar) (Summary, error) {
+	var sum Summary
+	startedUtc := time.Now().UTC()
+
+	done, err := index.JournalPaths(opts.Journal)
+	if err != nil {
+		return sum, fmt.Errorf("read journal: %w", err)
+	}
+
+	files, scanErrs := scan.Walk(opts.Root, opts.Scan)
+	sum.Found = len(files)
+	sum.ScanErrors = scanErrs
+
+	todo := files[:0:0]
+	for _, f := range files {
+		if done.Has(f.Path) {
+			sum.Resumed++
+			continue
+		}
+		todo = append(todo, f)
+		atomic.AddInt64(&stats.Total, 1)
+		atomic.AddInt64(&stats.TotalBytes, f.Length)
+		if bar != nil {
+			bar.AddTotal(f.Length)
+		}
+	}
+
+	jw, err := index.OpenJournalWriter(opts.Journal)
+	if err != nil {
+		return sum, fmt.Errorf("open journal: %w", err)
+	}
+
+	results := hashFiles(opts.Algorithm, opts.Extra, todo, opts.Workers, stats, bar)
+	var appendErr error
+	for fi := range results {
+		if appendErr != nil {
+			continue
+		}
+		if err := jw.Append(fi); err != nil {
+			appendErr = fmt.Errorf("append journal: %w", err)
+			continue
+		}
+		sum.Hashed++
+		if fi.Error != nil {
+			sum.Errors++
+		}
+	}
+	if err := jw.Close(); err != nil && appendErr == nil {
+		appendErr = err
+	}
+	if appendErr != nil {
+		return sum, appendErr
+	}
+
+	_, items, err := index.LoadFormat(opts.Journal, index.FormatJournal)
+	if err != nil {
+		return sum, err
+	}
+	run := index.RunInfo{Algorithm: opts.Algorithm, Root: opts.Root, StartedUtc: startedUtc}
+	return sum, index.Save(opts.Out, run, items)
+}
+
+// hashFiles hashes files with the given number of workers and delivers one
+// item per file, in completion order. The channel closes when all are done.
+func hashFiles(algorithm string, extra []string, files []scan.File, workers int, stats *metrics.Stats, bar *progress.Bar) <-chan index.FileItem {
+	if workers <= 0 {
+		workers = 1
+	}
+	jobs := make(chan scan.File)
+	results := make(chan index.FileItem)
+
+	go func() {
+		defer close(jobs)
+		for _, f := range files {
+			jobs <- f
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				var hashed int64
+				digests, err := verify.FileHashesHex(f.Path, append([]string{algorithm}, extra...), func(n int64) {
+					atomic.AddInt64(&stats.BytesHashed, n)
+					hashed += n
+					if bar != nil {
+						bar.AddBytes(n)
+					}
+				})
+				if bar != nil {
+					bar.AddBytes(f.Length - hashed)
+				}
+
+				fi := index.FileItem{Ok: err == nil, Path: f.Path, Length: f.Length, ModTime: f.ModTime}
+				if err == nil {
+					fi.Hash = digests[index.AlgorithmKey(algorithm)]
+					for _, alg := range extra {
+						if fi.Hashes == nil {
+							fi.Hashes = map[string]string{}
+						}
+						fi.Hashes[index.AlgorithmKey(alg)] = digests[index.AlgorithmKey(alg)]
+					}
+				}
+				if err != nil {
+					msg := err.Error()
+					fi.Error = &msg
+					atomic.AddInt64(&stats.HashErrors, 1)
+				} else {
+					atomic.AddInt64(&stats.OK, 1)
+				}
+				atomic.AddInt64(&stats.Processed, 1)
+				results <- fi
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
@@ -0,0 +1,169 @@
+package build
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/scan"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_Resumes(t *testing.T) {
+	root := t.TempDir()
+	out := t.TempDir()
+	contents := map[string]string{"a.mkv": "aaaa", "b.mkv": "bb", "skip.txt": "x"}
+	for name, data := range contents {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	// a.mkv was hashed by an earlier, interrupted run; its recorded hash must
+	// be kept rather than recomputed.
+	journal := filepath.Join(out, "AnimeHashIndex.journal.ndjson")
+	jw, err := index.OpenJournalWriter(journal)
+	if err != nil {
+		t.Fatalf("OpenJournalWriter: %v", err)
+	}
+	if err := jw.Append(index.FileItem{Ok: true, Path: filepath.Join(root, "a.mkv"), Length: 4, Hash: "FROMJOURNAL"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := jw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	opts := Options{
+		Root:      root,
+		Journal:   journal,
+		Out:       filepath.Join(out, "AnimeHashIndex.clixml"),
+		Algorithm: "SHA256",
+		Workers:   2,
+		Scan:      scan.DefaultOptions(),
+	}
+	stats := &metrics.Stats{}
+	sum, err := Run(opts, stats, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sum.Found != 2 || sum.Resumed != 1 || sum.Hashed != 1 || sum.Errors != 0 {
+		t.Fatalf("summary mismatch: %+v", sum)
+	}
+
+	run, items, err := index.Load(opts.Out)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if run.Algorithm != "SHA256" || run.Root != root || run.Total != 2 {
+		t.Fatalf("run mismatch: %+v", run)
+	}
+	digest := sha256.Sum256([]byte("bb"))
+	want := map[string]string{
+		filepath.Join(root, "a.mkv"): "FROMJOURNAL",
+		filepath.Join(root, "b.mkv"): strings.ToUpper(hex.EncodeToString(digest[:])),
+	}
+	for _, fi := range items {
+		if want[fi.Path] != fi.Hash {
+			t.Fatalf("item %s hash mismatch: got %q want %q", fi.Path, fi.Hash, want[fi.Path])
+		}
+	}
+
+	// A second run finds nothing left to hash.
+	sum, err = Run(opts, &metrics.Stats{}, nil)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if sum.Resumed != 2 || sum.Hashed != 0 {
+		t.Fatalf("second summary mismatch: %+v", sum)
+	}
+}
+
+func TestUpdate_RehashesOnlyChanged(t *testing.T) {
+	root := t.TempDir()
+	out := t.TempDir()
+	write := func(name, data string) string {
+		p := filepath.Join(root, name)
+		if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		return p
+	}
+	keep := write("keep.mkv", "keep")
+	touched := write("touched.mkv", "same")
+	grown := write("grown.mkv", "grown")
+	legacy := write("legacy.mkv", "old")
+
+	modTime := func(p string) time.Time {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		return info.ModTime()
+	}
+	base := filepath.Join(out, "base.clixml")
+	baseItems := []index.FileItem{
+		{Ok: true, Path: keep, Length: 4, Hash: "KEEP", ModTime: modTime(keep)},
+		{Ok: true, Path: touched, Length: 4, Hash: "TOUCHED", ModTime: modTime(touched).Add(-time.Hour)},
+		{Ok: true, Path: grown, Length: 4, Hash: "GROWN", ModTime: modTime(grown)},
+		{Ok: true, Path: legacy, Length: 3, Hash: "LEGACY"},
+		{Ok: true, Path: filepath.Join(root, "gone.mkv"), Length: 1, Hash: "GONE"},
+	}
+	if err := index.Save(base, index.RunInfo{Algorithm: "SHA256", Root: root}, baseItems); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	added := write("new.mkv", "new")
+
+	opts := Options{Root: root, Out: filepath.Join(out, "updated.clixml"), Workers: 2, Scan: scan.DefaultOptions()}
+	sum, err := Update(base, opts, &metrics.Stats{}, nil)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if sum.New != 1 || sum.Changed != 2 || sum.Unchanged != 2 || sum.Deleted != 1 || sum.Errors != 0 {
+		t.Fatalf("summary mismatch: %+v", sum)
+	}
+
+	run, items, err := index.Load(opts.Out)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if run.Algorithm != "SHA256" || len(items) != 5 {
+		t.Fatalf("updated index mismatch: alg=%q items=%+v", run.Algorithm, items)
+	}
+	sha := func(s string) string {
+		d := sha256.Sum256([]byte(s))
+		return strings.ToUpper(hex.EncodeToString(d[:]))
+	}
+	want := map[string]string{keep: "KEEP", legacy: "LEGACY", touched: sha("same"), grown: sha("grown"), added: sha("new")}
+	for _, fi := range items {
+		if want[fi.Path] != fi.Hash {
+			t.Fatalf("item %s hash mismatch: got %q want %q", fi.Path, fi.Hash, want[fi.Path])
+		}
+		if fi.ModTime.IsZero() {
+			t.Fatalf("item %s has no mtime", fi.Path)
+		}
+	}
+
+	// A new extra algorithm is hashed for unchanged files too.
+	opts.Out = filepath.Join(out, "extra.clixml")
+	opts.Extra = []string{"MD5", "SHA256"}
+	sum, err = Update(filepath.Join(out, "updated.clixml"), opts, &metrics.Stats{}, nil)
+	if err != nil {
+		t.Fatalf("Update with -extra-alg: %v", err)
+	}
+	if sum.Rehashed != 5 || sum.Unchanged != 0 || sum.Changed != 0 {
+		t.Fatalf("summary mismatch: %+v", sum)
+	}
+	_, items, err = index.Load(opts.Out)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, fi := range items {
+		if fi.Hashes["MD5"] == "" || fi.Hash != sha(map[string]string{keep: "keep", legacy: "old", touched: "same", grown: "grown", added: "new"}[fi.Path]) {
+			t.Fatalf("item %s not rehashed: %+v", fi.Path, fi)
+		}
+	}
+}
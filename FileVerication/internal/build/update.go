@@ -0,0 +1,127 @@
+package build
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/progress"
+	"FileVerication/internal/scan"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type UpdateSummary struct {
+	New       int
+	Changed   int
+	Unchanged int
+	// Rehashed counts unchanged files hashed again because they lack a
+	// digest for one of opts.Extra.
+	Rehashed   int
+	Deleted    int
+	Errors     int
+	ScanErrors []error
+}
+
+// Update refreshes the index at base instead of rebuilding it: files whose
+// size and modification time match the base entry keep their hash, new and
+// changed files are hashed, and paths no longer on disk are dropped. Entries
+// that failed before, and unchanged ones missing a digest for one of
+// opts.Extra, are hashed again. An empty opts.Algorithm takes the base's. Base entries without a recorded
+// modification time are trusted on size alone and pick up the current one.
+// The result is written to opts.Out; opts.Journal is not used.
+func Update(base string, opts Options, stats *metrics.Stats, bar *progress.Bar) (UpdateSummary, error) {
+	var sum UpdateSummary
+	startedUtc := time.Now().UTC()
+
+	baseRun, baseItems, err := index.Load(base)
+	if err != nil {
+		return sum, fmt.Errorf("load base index: %w", err)
+	}
+	switch {
+	case opts.Algorithm == "":
+		opts.Algorithm = baseRun.Algorithm
+	case baseRun.Algorithm != "" && !strings.EqualFold(opts.Algorithm, baseRun.Algorithm):
+		return sum, fmt.Errorf("base index uses %s, not %s", baseRun.Algorithm, opts.Algorithm)
+	}
+	if opts.Algorithm == "" {
+		return sum, fmt.Errorf("%s does not record a hash algorithm", base)
+	}
+	opts.Extra = slices.DeleteFunc(slices.Clone(opts.Extra), func(alg string) bool {
+		return index.AlgorithmKey(alg) == index.AlgorithmKey(opts.Algorithm)
+	})
+
+	known := make(map[string]index.FileItem, len(baseItems))
+	for _, fi := range baseItems {
+		known[index.PathKey(fi.Path)] = fi
+	}
+
+	files, scanErrs := scan.Walk(opts.Root, opts.Scan)
+	sum.ScanErrors = scanErrs
+
+	items := make([]index.FileItem, 0, len(files))
+	slot := map[string]int{}
+	var todo []scan.File
+	for _, f := range files {
+		key := index.PathKey(f.Path)
+		prev, ok := known[key]
+		delete(known, key)
+
+		switch {
+		case !ok:
+			sum.New++
+		case unchanged(prev, f) && !hasDigests(prev, opts.Extra):
+			sum.Rehashed++
+		case unchanged(prev, f):
+			sum.Unchanged++
+			prev.ModTime = f.ModTime
+			items = append(items, prev)
+			continue
+		default:
+			sum.Changed++
+		}
+
+		slot[f.Path] = len(items)
+		items = append(items, index.FileItem{Path: f.Path})
+		todo = append(todo, f)
+		atomic.AddInt64(&stats.Total, 1)
+		atomic.AddInt64(&stats.TotalBytes, f.Length)
+		if bar != nil {
+			bar.AddTotal(f.Length)
+		}
+	}
+	sum.Deleted = len(known)
+
+	for fi := range hashFiles(opts.Algorithm, opts.Extra, todo, opts.Workers, stats, bar) {
+		items[slot[fi.Path]] = fi
+		if fi.Error != nil {
+			sum.Errors++
+		}
+	}
+
+	run := index.RunInfo{Algorithm: opts.Algorithm, Root: opts.Root, StartedUtc: startedUtc}
+	return sum, index.Save(opts.Out, run, items)
+}
+
+// hasDigests reports whether fi records a digest for every algorithm in algs.
+func hasDigests(fi index.FileItem, algs []string) bool {
+	for _, alg := range algs {
+		if fi.Hashes[index.AlgorithmKey(alg)] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// unchanged reports whether f still looks like the file prev was hashed
+// from. Times are compared at the 100ns resolution CLIXML stores.
+func unchanged(prev index.FileItem, f scan.File) bool {
+	if !prev.Ok || prev.Error != nil || prev.Hash == "" || prev.Length != f.Length {
+		return false
+	}
+	if prev.ModTime.IsZero() {
+		return true
+	}
+	return prev.ModTime.Truncate(100 * time.Nanosecond).Equal(f.ModTime.Truncate(100 * time.Nanosecond))
+}
@@ -0,0 +1,38 @@
+package hashalg
+
+import (
+	"crypto/md5"  // #nosec G501 -- used for file integrity verification only
+	"crypto/sha1" // #nosec G505 -- used for file integrity verification only
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+	"github.com/zeebo/xxh3"
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func init() {
+	for _, a := range []Algorithm{
+		{Name: "SHA256", New: sha256.New, Crypto: true},
+		{Name: "SHA1", New: sha1.New, Crypto: true}, // #nosec G401 -- used for file integrity verification only
+		{Name: "SHA512", New: sha512.New, Crypto: true},
+		{Name: "SHA384", New: sha512.New384, Crypto: true},
+		{Name: "MD5", New: md5.New, Crypto: true}, // #nosec G401 -- used for file integrity verification only
+		{Name: "BLAKE3", New: func() hash.Hash { return blake3.New() }, Crypto: true},
+		// Digests are big-endian, matching xxhsum and crc32c tools.
+		{Name: "XXH64", New: func() hash.Hash { return xxhash.New() }},
+		{Name: "XXH3", New: func() hash.Hash { return xxh3.New() }},
+		{Name: "CRC32C", New: func() hash.Hash { return crc32.New(castagnoli) }},
+		// CRC32 is the IEEE polynomial that SFV files and release tags use.
+		{Name: "CRC32", New: func() hash.Hash { return crc32.NewIEEE() }},
+		// MD4-based file IDs for ed2k:// links.
+		{Name: "ED2K", New: func() hash.Hash { return newED2K(false) }},
+		{Name: "ED2KRED", New: func() hash.Hash { return newED2K(true) }},
+	} {
+		Register(a)
+	}
+}
@@ -0,0 +1,86 @@
+// Package hashalg is the registry of hash algorithms indexes can be built
+// and verified with. The usual cryptographic hashes and a few fast
+// checksums are built in; other packages can add their own with Register.
+package hashalg
+
+import (
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Algorithm describes one registered hash.
+type Algorithm struct {
+	// Name is how indexes and flags refer to the algorithm, e.g. SHA256.
+	Name string
+	New  func() hash.Hash
+	// Size is the digest length in bytes; Register fills it in from New.
+	Size int
+	// Crypto is false for checksums that only detect accidental corruption.
+	Crypto bool
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Algorithm{}
+)
+
+// Register makes an algorithm available under Key(a.Name). It panics if
+// the name is already taken or New is nil, as registering twice is a bug.
+func Register(a Algorithm) {
+	if a.New == nil {
+		panic("hashalg: Register " + a.Name + " with nil New")
+	}
+	key := Key(a.Name)
+	if key == "" {
+		panic("hashalg: Register with empty name")
+	}
+	a.Name = key
+	if a.Size == 0 {
+		a.Size = a.New().Size()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := registry[key]; dup {
+		panic("hashalg: Register called twice for " + key)
+	}
+	registry[key] = a
+}
+
+// Lookup returns the algorithm registered under name, compared by Key.
+func Lookup(name string) (Algorithm, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	a, ok := registry[Key(name)]
+	return a, ok
+}
+
+// New returns a fresh hash for the named algorithm.
+func New(name string) (hash.Hash, error) {
+	a, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm: %q (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+	return a.New(), nil
+}
+
+// Names lists the registered algorithms, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Key is the canonical form of an algorithm name: upper case, without
+// dashes or underscores, so SHA-256 and sha256 are the same algorithm.
+func Key(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "_", "").Replace(strings.TrimSpace(name)))
+}
@@ -0,0 +1,94 @@
+package hashalg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"slices"
+	"testing"
+)
+
+func TestBuiltins_KnownDigests(t *testing.T) {
+	tests := []struct {
+		alg, input, want string
+	}{
+		{"sha-256", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"MD5", "abc", "900150983cd24fb0d6963f7d28e17f72"},
+		{"blake3", "", "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{"BLAKE3", "abc", "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{"xxh64", "", "ef46db3751d8e999"},
+		{"XXH3", "", "2d06800538d394c2"},
+		{"crc32c", "123456789", "e3069283"},
+		{"crc32", "123456789", "cbf43926"},
+		{"CRC32-C", "", "00000000"},
+	}
+	for _, tt := range tests {
+		h, err := New(tt.alg)
+		if err != nil {
+			t.Fatalf("New(%q): %v", tt.alg, err)
+		}
+		h.Write([]byte(tt.input))
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.want {
+			t.Errorf("%s(%q) = %s, want %s", tt.alg, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if _, err := New("sha3-test"); err == nil {
+		t.Fatalf("unregistered algorithm accepted")
+	}
+	Register(Algorithm{Name: "sha3_test", New: func() hash.Hash { return sha256.New() }})
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(registry, "SHA3TEST")
+		mu.Unlock()
+	})
+
+	a, ok := Lookup("SHA3-Test")
+	if !ok || a.Name != "SHA3TEST" || a.Size != sha256.Size || a.Crypto {
+		t.Fatalf("Lookup: %+v %v", a, ok)
+	}
+	if !slices.Contains(Names(), "SHA3TEST") {
+		t.Fatalf("Names() = %v", Names())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("registering a name twice did not panic")
+		}
+	}()
+	Register(Algorithm{Name: "SHA3-TEST", New: sha256.New})
+}
+
+func TestED2K_ChunkBoundaries(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		blue, red string
+	}{
+		{"empty", 0, "31d6cfe0d16ae931b73c59d7e0c089c0", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"one chunk", ED2KChunkSize, "d7def262a127cd79096a108e7a9fc138", "fc21d9af828f92a8df64beac3357425d"},
+		{"two chunks", 2 * ED2KChunkSize, "194ee9e4fa79b2ee9f8829284c466051", "114b21c63a74b6ca922291a11177dd5c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for alg, want := range map[string]string{"ED2K": tt.blue, "ED2KRED": tt.red} {
+				h, err := New(alg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Odd write sizes cross the chunk boundary mid-write.
+				for rest := data; len(rest) > 0; {
+					n := min(len(rest), 1<<20+3)
+					h.Write(rest[:n])
+					rest = rest[n:]
+				}
+				if got := hex.EncodeToString(h.Sum(nil)); got != want {
+					t.Errorf("%s of %d zero bytes = %s, want %s", alg, tt.size, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package index
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{"ok", "path", "length", "hash", "error", "mtime", "hashes"}
+
+// OpenCSV reads the CSV form WriteCSV produces. Columns are matched by header
+// name, so files re-exported by Export-Csv with a #TYPE line or in another
+// column order also load. An empty error column reads as no error.
+func OpenCSV(path string) (Source, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv: read header: %w", err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))] = i
+	}
+	if _, ok := col["path"]; !ok {
+		return nil, fmt.Errorf("csv: no path column in header %v", header)
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+
+	s := &sliceSource{run: RunInfo{Format: FormatCSV, Meta: map[string]any{}}}
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %w", err)
+		}
+
+		fi := FileItem{Path: field(rec, "path"), Hash: field(rec, "hash")}
+		if fi.Path == "" {
+			s.run.Malformed++
+			continue
+		}
+		if b, ok := toBool(field(rec, "ok")); ok {
+			fi.Ok = b
+		}
+		if n := strings.TrimSpace(field(rec, "length")); n != "" {
+			if fi.Length, err = strconv.ParseInt(n, 10, 64); err != nil {
+				return nil, fmt.Errorf("csv: %s: invalid length %q", fi.Path, n)
+			}
+		}
+		if e := field(rec, "error"); e != "" {
+			fi.Error = &e
+		}
+		if m := field(rec, "mtime"); m != "" {
+			fi.ModTime = toTime(m)
+		}
+		if fi.Hashes, err = parseHashes(field(rec, "hashes")); err != nil {
+			return nil, fmt.Errorf("csv: %s: %w", fi.Path, err)
+		}
+		s.items = append(s.items, fi)
+	}
+
+	s.sumBytes()
+	return s, nil
+}
+
+func WriteCSV(w io.Writer, items []FileItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, fi := range items {
+		var errText string
+		if fi.Error != nil {
+			errText = *fi.Error
+		}
+		rec := []string{strconv.FormatBool(fi.Ok), fi.Path, strconv.FormatInt(fi.Length, 10), fi.Hash, errText, formatTime(fi.ModTime), formatHashes(fi.Hashes)}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatHashes writes extra digests as ALG=hex pairs separated by
+// semicolons, sorted by algorithm.
+func formatHashes(hashes map[string]string) string {
+	pairs := make([]string, 0, len(hashes))
+	for _, alg := range slices.Sorted(maps.Keys(hashes)) {
+		pairs = append(pairs, alg+"="+hashes[alg])
+	}
+	return strings.Join(pairs, ";")
+}
+
+func parseHashes(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	hashes := map[string]string{}
+	for _, pair := range strings.Split(s, ";") {
+		alg, h, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(alg) == "" {
+			return nil, fmt.Errorf("invalid hashes entry %q, want ALG=hex", pair)
+		}
+		hashes[AlgorithmKey(alg)] = strings.TrimSpace(h)
+	}
+	return hashes, nil
+}
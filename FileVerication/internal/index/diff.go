@@ -0,0 +1,128 @@
+package index
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeBitRot   ChangeKind = "hash_changed_same_length"
+	ChangeReplaced ChangeKind = "hash_changed_length_changed"
+	ChangeMoved    ChangeKind = "moved"
+)
+
+// ChangeKinds lists the kinds in the order reports present them.
+var ChangeKinds = []ChangeKind{ChangeBitRot, ChangeReplaced, ChangeMoved, ChangeAdded, ChangeRemoved}
+
+// Change is one difference between two indexes. Old* fields describe the
+// file in the older index and are empty for additions; the plain fields
+// describe the newer one and are empty for removals.
+type Change struct {
+	Kind      ChangeKind `json:"kind"`
+	Path      string     `json:"path,omitempty"`
+	OldPath   string     `json:"oldPath,omitempty"`
+	Hash      string     `json:"hash,omitempty"`
+	OldHash   string     `json:"oldHash,omitempty"`
+	Length    int64      `json:"length"`
+	OldLength int64      `json:"oldLength"`
+}
+
+// Diff compares two runs of the same tree. Paths are matched relative to
+// each run's root, ignoring case and separator style, so an index rebuilt
+// from another mount still lines up. Hashes are only compared when both
+// sides hashed successfully. A removed path whose hash reappears under an
+// added path is reported once, as a move.
+func Diff(oldRun RunInfo, oldItems []FileItem, newRun RunInfo, newItems []FileItem) []Change {
+	oldByKey := make(map[string]FileItem, len(oldItems))
+	for _, fi := range oldItems {
+		oldByKey[relKey(oldRun.Root, fi.Path)] = fi
+	}
+	newKeys := make(map[string]struct{}, len(newItems))
+
+	var changes, added []Change
+	for _, fi := range newItems {
+		key := relKey(newRun.Root, fi.Path)
+		newKeys[key] = struct{}{}
+
+		prev, ok := oldByKey[key]
+		if !ok {
+			added = append(added, Change{Kind: ChangeAdded, Path: fi.Path, Hash: fi.Hash, Length: fi.Length})
+			continue
+		}
+		if !hashed(prev) || !hashed(fi) || strings.EqualFold(prev.Hash, fi.Hash) {
+			continue
+		}
+		c := Change{
+			Kind: ChangeReplaced, Path: fi.Path, OldPath: prev.Path,
+			Hash: fi.Hash, OldHash: prev.Hash, Length: fi.Length, OldLength: prev.Length,
+		}
+		if prev.Length == fi.Length {
+			c.Kind = ChangeBitRot
+		}
+		changes = append(changes, c)
+	}
+
+	removedByHash := map[string][]FileItem{}
+	var removed []FileItem
+	for _, fi := range oldItems {
+		if _, ok := newKeys[relKey(oldRun.Root, fi.Path)]; ok {
+			continue
+		}
+		removed = append(removed, fi)
+		if hashed(fi) {
+			h := strings.ToUpper(fi.Hash)
+			removedByHash[h] = append(removedByHash[h], fi)
+		}
+	}
+
+	moved := map[string]struct{}{}
+	for _, c := range added {
+		h := strings.ToUpper(c.Hash)
+		if from := removedByHash[h]; c.Hash != "" && len(from) > 0 {
+			removedByHash[h] = from[1:]
+			moved[from[0].Path] = struct{}{}
+			changes = append(changes, Change{
+				Kind: ChangeMoved, Path: c.Path, OldPath: from[0].Path,
+				Hash: c.Hash, OldHash: from[0].Hash, Length: c.Length, OldLength: from[0].Length,
+			})
+			continue
+		}
+		changes = append(changes, c)
+	}
+	for _, fi := range removed {
+		if _, ok := moved[fi.Path]; ok {
+			continue
+		}
+		changes = append(changes, Change{Kind: ChangeRemoved, OldPath: fi.Path, OldHash: fi.Hash, OldLength: fi.Length})
+	}
+
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		if c := cmp.Compare(slices.Index(ChangeKinds, a.Kind), slices.Index(ChangeKinds, b.Kind)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path+a.OldPath, b.Path+b.OldPath)
+	})
+	return changes
+}
+
+func hashed(fi FileItem) bool {
+	return fi.Ok && fi.Error == nil && fi.Hash != ""
+}
+
+// relKey is PathKey relative to root when p lies under it.
+func relKey(root, p string) string {
+	key := PathKey(p)
+	if root == "" {
+		return key
+	}
+	prefix := strings.TrimRight(PathKey(root), "/") + "/"
+	if rest, ok := strings.CutPrefix(key, prefix); ok {
+		return rest
+	}
+	return key
+}
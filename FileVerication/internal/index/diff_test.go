@@ -0,0 +1,48 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldRun := index.RunInfo{Root: `\\nas\anime`}
+	newRun := index.RunInfo{Root: `/mnt/anime`}
+	oldItems := []index.FileItem{
+		{Ok: true, Path: `\\nas\anime\same.mkv`, Length: 1, Hash: "AA"},
+		{Ok: true, Path: `\\nas\anime\rot.mkv`, Length: 2, Hash: "BB"},
+		{Ok: true, Path: `\\nas\anime\replaced.mkv`, Length: 3, Hash: "CC"},
+		{Ok: true, Path: `\\nas\anime\old\moved.mkv`, Length: 4, Hash: "DD"},
+		{Ok: true, Path: `\\nas\anime\gone.mkv`, Length: 5, Hash: "EE"},
+		{Ok: false, Path: `\\nas\anime\locked.mkv`, Error: strPtr("in use")},
+	}
+	newItems := []index.FileItem{
+		{Ok: true, Path: `/mnt/anime/Same.mkv`, Length: 1, Hash: "aa"},
+		{Ok: true, Path: `/mnt/anime/rot.mkv`, Length: 2, Hash: "B0"},
+		{Ok: true, Path: `/mnt/anime/replaced.mkv`, Length: 30, Hash: "C0"},
+		{Ok: true, Path: `/mnt/anime/new/moved.mkv`, Length: 4, Hash: "DD"},
+		{Ok: true, Path: `/mnt/anime/fresh.mkv`, Length: 6, Hash: "FF"},
+		{Ok: true, Path: `/mnt/anime/locked.mkv`, Length: 7, Hash: "GG"},
+	}
+
+	got := index.Diff(oldRun, oldItems, newRun, newItems)
+	want := []struct {
+		kind    index.ChangeKind
+		path    string
+		oldPath string
+	}{
+		{index.ChangeBitRot, `/mnt/anime/rot.mkv`, `\\nas\anime\rot.mkv`},
+		{index.ChangeReplaced, `/mnt/anime/replaced.mkv`, `\\nas\anime\replaced.mkv`},
+		{index.ChangeMoved, `/mnt/anime/new/moved.mkv`, `\\nas\anime\old\moved.mkv`},
+		{index.ChangeAdded, `/mnt/anime/fresh.mkv`, ""},
+		{index.ChangeRemoved, "", `\\nas\anime\gone.mkv`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes length mismatch: got %d want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].Path != w.path || got[i].OldPath != w.oldPath {
+			t.Fatalf("change[%d] mismatch: got %+v want %+v", i, got[i], w)
+		}
+	}
+}
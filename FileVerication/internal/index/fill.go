@@ -0,0 +1,26 @@
+package index
+
+import "os"
+
+// FillLength sets Length from the file on disk, for index formats that only
+// record a path and a hash. Items whose file cannot be stat'ed are left as
+// they are; Verify reports them as stat errors.
+func FillLength(fi *FileItem) error {
+	info, err := os.Stat(fi.FSPath())
+	if err != nil {
+		return err
+	}
+	fi.Length = info.Size()
+	return nil
+}
+
+// FillLengths applies FillLength to every item and returns how many could
+// not be stat'ed.
+func FillLengths(items []FileItem) (failed int) {
+	for i := range items {
+		if err := FillLength(&items[i]); err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,194 @@
+package index
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source is an open index of any supported format.
+type Source interface {
+	Run() RunInfo
+	All() iter.Seq2[FileItem, error]
+	Close() error
+}
+
+// sliceSource serves formats that are read in full on open.
+type sliceSource struct {
+	run   RunInfo
+	items []FileItem
+}
+
+func (s *sliceSource) Run() RunInfo {
+	return s.run
+}
+
+func (s *sliceSource) All() iter.Seq2[FileItem, error] {
+	return func(yield func(FileItem, error) bool) {
+		for _, fi := range s.items {
+			if !yield(fi, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *sliceSource) Close() error {
+	return nil
+}
+
+func (s *sliceSource) sumBytes() {
+	for _, fi := range s.items {
+		if fi.Error == nil {
+			s.run.TotalBytes += fi.Length
+		}
+	}
+}
+
+// Open opens an index, choosing the format from the file extension and
+// falling back to sniffing the content.
+func Open(path string) (Source, error) {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	return OpenFormat(path, format)
+}
+
+func OpenFormat(path string, format Format) (Source, error) {
+	switch format {
+	case FormatCLIXML, FormatHashtable:
+		return OpenCLIXML(path)
+	case FormatJournal:
+		return OpenJournal(path)
+	case FormatJSON:
+		return OpenJSON(path)
+	case FormatCSV:
+		return OpenCSV(path)
+	case FormatSumFile:
+		return OpenSumFile(path)
+	}
+	return nil, fmt.Errorf("unsupported index format %q", format)
+}
+
+// ParseFormat accepts a format name as used on command lines.
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	switch name {
+	case "xml":
+		return FormatCLIXML, nil
+	case "hashtable":
+		return FormatHashtable, nil
+	case "journal", "jsonl":
+		return FormatJournal, nil
+	case "sumfile", "sum", "sha1sum", "md5sum", "sha512sum", "sha384sum":
+		return FormatSumFile, nil
+	}
+	return "", fmt.Errorf("unknown index format %q", name)
+}
+
+// FormatFromExt maps a file name to a format, or "" when the extension says
+// nothing. Hashtable CLIXML cannot be told apart by name and maps to
+// FormatCLIXML; OpenCLIXML detects it.
+func FormatFromExt(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatJournal
+	case ".clixml", ".xml":
+		return FormatCLIXML
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	}
+	if algorithmFromExt(path) != "" {
+		return FormatSumFile
+	}
+	return ""
+}
+
+func DetectFormat(path string) (Format, error) {
+	if f := FormatFromExt(path); f != "" {
+		return f, nil
+	}
+
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	br := bufio.NewReader(f)
+	for {
+		r, _, err := br.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return FormatCLIXML, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\uFEFF', ' ', '\t', '\r', '\n':
+			continue
+		case '<':
+			return FormatCLIXML, nil
+		case '[':
+			return FormatJSON, nil
+		case '{':
+			// A journal line is a complete item on its own line; a JSON
+			// document spreads over several, or is one object without a path.
+			line, _ := br.ReadString('\n')
+			var probe map[string]json.RawMessage
+			if json.Unmarshal([]byte("{"+line), &probe) == nil {
+				if _, ok := probe["path"]; ok {
+					return FormatJournal, nil
+				}
+			}
+			return FormatJSON, nil
+		}
+
+		_ = br.UnreadRune()
+		line, _ := br.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if hash, rest, ok := strings.Cut(strings.TrimPrefix(line, `\`), " "); ok && isHex(hash) &&
+			(strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "*")) {
+			return FormatSumFile, nil
+		}
+		if strings.Contains(strings.ToLower(line), "path") && strings.Contains(line, ",") {
+			return FormatCSV, nil
+		}
+		return FormatCLIXML, nil
+	}
+}
+
+// Write encodes an index in the given format.
+func Write(w io.Writer, format Format, run RunInfo, items []FileItem) error {
+	switch format {
+	case FormatCLIXML:
+		return Encode(run, items).Encode(w)
+	case FormatHashtable:
+		return EncodeHashtable(items).Encode(w)
+	case FormatJournal:
+		return WriteJournal(w, items)
+	case FormatJSON:
+		return WriteJSON(w, run, items)
+	case FormatCSV:
+		return WriteCSV(w, items)
+	case FormatSumFile:
+		return WriteSumFile(w, items)
+	}
+	return fmt.Errorf("unsupported index format %q", format)
+}
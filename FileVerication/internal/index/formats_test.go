@@ -0,0 +1,144 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveFormat_RoundTrip(t *testing.T) {
+	run := index.RunInfo{
+		Algorithm:  "SHA256",
+		Root:       `\\nas\anime`,
+		CreatedUtc: time.Date(2026, 2, 16, 23, 9, 8, 420985700, time.UTC),
+	}
+	items := []index.FileItem{
+		{Ok: true, Path: `\\nas\anime\a.mkv`, Length: 10, Hash: "AAA0", ModTime: time.Date(2025, 12, 1, 8, 30, 0, 123456700, time.UTC), Hashes: map[string]string{"MD5": "A5", "SHA1": "A1"}},
+		{Ok: false, Path: `\\nas\anime\b, "quoted".mkv`, Length: 20, Error: strPtr("access denied")},
+		{Ok: true, Path: `\\nas\anime\line` + "\n" + `break.mkv`, Length: 30, Hash: "CCC0", Hashes: map[string]string{"MD5": "C5"}},
+	}
+
+	tests := []struct {
+		name       string
+		format     index.Format
+		okOnly     bool
+		keepsMeta  bool
+		keepsSizes bool
+	}{
+		{"out.clixml", index.FormatCLIXML, false, true, true},
+		{"out.json", index.FormatJSON, false, true, true},
+		{"out.ndjson", index.FormatJournal, false, false, true},
+		{"out.csv", index.FormatCSV, false, false, true},
+		{"out.sha256", index.FormatSumFile, true, false, false},
+		{"hashes.clixml", index.FormatHashtable, true, false, false},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		if err := index.SaveFormat(p, tt.format, run, items); err != nil {
+			t.Fatalf("SaveFormat(%s): %v", tt.format, err)
+		}
+
+		gotRun, gotItems, err := index.Load(p)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", tt.format, err)
+		}
+		if gotRun.Format != tt.format {
+			t.Fatalf("%s: Format mismatch: got %q", tt.format, gotRun.Format)
+		}
+		if tt.keepsMeta && (gotRun.Root != run.Root || !gotRun.CreatedUtc.Equal(run.CreatedUtc)) {
+			t.Fatalf("%s: run mismatch: got %q %v", tt.format, gotRun.Root, gotRun.CreatedUtc)
+		}
+		if tt.format != index.FormatJournal && tt.format != index.FormatCSV && tt.format != index.FormatHashtable && gotRun.Algorithm != run.Algorithm {
+			t.Fatalf("%s: Algorithm mismatch: got %q", tt.format, gotRun.Algorithm)
+		}
+
+		want := items
+		if tt.okOnly {
+			want = []index.FileItem{items[0], items[2]}
+		}
+		if len(gotItems) != len(want) {
+			t.Fatalf("%s: items length mismatch: got %d want %d", tt.format, len(gotItems), len(want))
+		}
+		for i := range want {
+			got := gotItems[i]
+			if got.Ok != want[i].Ok || got.Path != want[i].Path || got.Hash != want[i].Hash {
+				t.Fatalf("%s: item[%d] mismatch:\n got:  %+v\n want: %+v", tt.format, i, got, want[i])
+			}
+			if tt.keepsSizes && got.Length != want[i].Length {
+				t.Fatalf("%s: item[%d] Length mismatch: got %d want %d", tt.format, i, got.Length, want[i].Length)
+			}
+			if tt.keepsSizes && !got.ModTime.Equal(want[i].ModTime) {
+				t.Fatalf("%s: item[%d] ModTime mismatch: got %v want %v", tt.format, i, got.ModTime, want[i].ModTime)
+			}
+			if tt.keepsSizes && !maps.Equal(got.Hashes, want[i].Hashes) {
+				t.Fatalf("%s: item[%d] Hashes mismatch: got %v want %v", tt.format, i, got.Hashes, want[i].Hashes)
+			}
+			if (got.Error == nil) != (want[i].Error == nil) || (got.Error != nil && *got.Error != *want[i].Error) {
+				t.Fatalf("%s: item[%d] Error mismatch: got %v want %v", tt.format, i, got.Error, want[i].Error)
+			}
+		}
+	}
+}
+
+func TestOpenSumFile_Escaped(t *testing.T) {
+	const sums = "d41d8cd98f00b204e9800998ecf8427e  plain.mkv\n" +
+		"\\0cc175b9c0f1b6a831c399e269772661 *dir\\\\a\\nb.mkv\n" +
+		"not a checksum line\n"
+
+	p := filepath.Join(t.TempDir(), "CHECKSUMS")
+	if err := os.WriteFile(p, []byte(sums), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	run, items, err := index.Load(p)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if run.Format != index.FormatSumFile || run.Algorithm != "MD5" || run.Malformed != 1 {
+		t.Fatalf("run mismatch: format=%q alg=%q malformed=%d", run.Format, run.Algorithm, run.Malformed)
+	}
+	if len(items) != 2 || items[1].Path != "dir\\a\nb.mkv" || items[1].Hash != strings.ToUpper("0cc175b9c0f1b6a831c399e269772661") {
+		t.Fatalf("items mismatch: %+v", items)
+	}
+}
+
+func TestLoad_JSONWithoutExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"minified", `{"algorithm":"SHA256","items":[{"ok":true,"path":"a.mkv","length":1,"hash":"AA"}]}`},
+		{"array", `[{"ok":true,"path":"a.mkv","length":1,"hash":"AA"}]`},
+		{"bom", "\uFEFF" + `{"algorithm":"SHA256","items":[{"ok":true,"path":"a.mkv","length":1,"hash":"AA"}]}`},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(t.TempDir(), "index")
+		if err := os.WriteFile(p, []byte(tt.content), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+
+		run, items, err := index.Load(p)
+		if err != nil {
+			t.Fatalf("%s: Load: %v", tt.name, err)
+		}
+		if run.Format != index.FormatJSON || run.Malformed != 0 {
+			t.Fatalf("%s: format=%q malformed=%d", tt.name, run.Format, run.Malformed)
+		}
+		if len(items) != 1 || items[0].Path != "a.mkv" || items[0].Hash != "AA" {
+			t.Fatalf("%s: items mismatch: %+v", tt.name, items)
+		}
+		// Members the document does not have must not be checked.
+		v := index.NewValidator(run)
+		for _, fi := range items {
+			v.Add(fi)
+		}
+		if problems := v.Finish(run); len(problems) != 0 {
+			t.Fatalf("%s: validation problems: %v", tt.name, problems)
+		}
+	}
+}
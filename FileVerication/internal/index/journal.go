@@ -0,0 +1,175 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// journalLine is one record appended by Build-VideoHashIndex-Parallel.ps1.
+type journalLine struct {
+	Ok     bool    `json:"ok"`
+	Path   string  `json:"path"`
+	Length *int64  `json:"length"`
+	Hash   *string `json:"hash"`
+	Error  *string `json:"error"`
+	Mtime  string  `json:"mtime,omitempty"`
+	// Hashes is only written by this tool; the PowerShell indexer records
+	// a single hash.
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+func newJournalLine(fi FileItem) journalLine {
+	jl := journalLine{Ok: fi.Ok, Path: fi.Path, Length: &fi.Length, Error: fi.Error, Mtime: formatTime(fi.ModTime), Hashes: fi.Hashes}
+	if fi.Hash != "" {
+		jl.Hash = &fi.Hash
+	}
+	return jl
+}
+
+func (jl journalLine) item() FileItem {
+	fi := FileItem{Ok: jl.Ok, Path: jl.Path, Error: jl.Error, Hashes: jl.Hashes}
+	if jl.Length != nil {
+		fi.Length = *jl.Length
+	}
+	if jl.Hash != nil {
+		fi.Hash = *jl.Hash
+	}
+	if jl.Mtime != "" {
+		fi.ModTime = toTime(jl.Mtime)
+	}
+	return fi
+}
+
+// OpenJournal reads an NDJSON resume journal in full, because a path hashed
+// again after a restart replaces its earlier line (last write wins). Lines
+// that are not valid JSON, such as a final line torn by a crash, or that
+// carry no path are counted in RunInfo.Malformed rather than failing the load.
+func OpenJournal(path string) (Source, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	s := &sliceSource{run: RunInfo{Format: FormatJournal, Meta: map[string]any{}}}
+	seen := map[string]int{}
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	first := true
+	for sc.Scan() {
+		line := sc.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			first = false
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var jl journalLine
+		if err := json.Unmarshal([]byte(line), &jl); err != nil || jl.Path == "" {
+			s.run.Malformed++
+			continue
+		}
+		fi := jl.item()
+
+		if i, ok := seen[fi.Path]; ok {
+			s.items[i] = fi
+			s.run.Duplicates++
+			continue
+		}
+		seen[fi.Path] = len(s.items)
+		s.items = append(s.items, fi)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	s.sumBytes()
+	return s, nil
+}
+
+// WriteJournal writes items as NDJSON lines in the journal shape.
+func WriteJournal(w io.Writer, items []FileItem) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, fi := range items {
+		if err := enc.Encode(newJournalLine(fi)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JournalWriter appends results to a resume journal. Every line is synced
+// before Append returns, so a crash loses at most the line being written.
+type JournalWriter struct {
+	f   *os.File
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+func OpenJournalWriter(path string) (*JournalWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644) // #nosec G302 G304
+	if err != nil {
+		return nil, err
+	}
+	w := &JournalWriter{f: f}
+	w.enc = json.NewEncoder(&w.buf)
+	w.enc.SetEscapeHTML(false)
+	return w, nil
+}
+
+func (w *JournalWriter) Append(fi FileItem) error {
+	w.buf.Reset()
+	if err := w.enc.Encode(newJournalLine(fi)); err != nil {
+		return err
+	}
+	if _, err := w.f.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	return w.f.Sync()
+}
+
+func (w *JournalWriter) Close() error {
+	return w.f.Close()
+}
+
+// PathSet holds paths compared the way Validator compares them.
+type PathSet map[string]struct{}
+
+func (s PathSet) Add(p string) {
+	s[PathKey(p)] = struct{}{}
+}
+
+func (s PathSet) Has(p string) bool {
+	_, ok := s[PathKey(p)]
+	return ok
+}
+
+// JournalPaths returns the paths already recorded in a journal, for resuming
+// a build. A missing journal yields an empty set.
+func JournalPaths(path string) (PathSet, error) {
+	set := PathSet{}
+	src, err := OpenJournal(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for fi := range src.All() {
+		set.Add(fi.Path)
+	}
+	return set, src.Close()
+}
@@ -0,0 +1,81 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenJournal(t *testing.T) {
+	const journal = "\uFEFF" + `{"ok":true,"path":"\\\\nas\\anime\\a.mkv","length":10,"hash":"AAA","error":null}
+{"ok":false,"path":"\\\\nas\\anime\\b.mkv","length":20,"hash":null,"error":"access denied"}
+
+not json at all
+{"ok":true,"length":5}
+{"ok":true,"path":"\\\\nas\\anime\\b.mkv","length":20,"hash":"BBB","error":null}
+{"ok":true,"path":"\\\\nas\\anime\\c.mkv","leng`
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "AnimeHashIndex.journal.ndjson")
+	if err := os.WriteFile(p, []byte(journal), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	run, items, err := index.Load(p)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if run.Format != index.FormatJournal {
+		t.Fatalf("Format mismatch: got %q want %q", run.Format, index.FormatJournal)
+	}
+	if run.Malformed != 3 || run.Duplicates != 1 {
+		t.Fatalf("counts mismatch: malformed=%d duplicates=%d", run.Malformed, run.Duplicates)
+	}
+	if run.TotalBytes != 30 {
+		t.Fatalf("TotalBytes mismatch: got %d want %d", run.TotalBytes, 30)
+	}
+
+	want := []index.FileItem{
+		{Ok: true, Path: `\\nas\anime\a.mkv`, Length: 10, Hash: "AAA"},
+		{Ok: true, Path: `\\nas\anime\b.mkv`, Length: 20, Hash: "BBB"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items length mismatch: got %d want %d", len(items), len(want))
+	}
+	for i := range want {
+		got := items[i]
+		if got.Ok != want[i].Ok || got.Path != want[i].Path || got.Length != want[i].Length || got.Hash != want[i].Hash || got.Error != nil {
+			t.Fatalf("item[%d] mismatch:\n got:  %+v\n want: %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestDetectFormat_Sniffs(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    index.Format
+	}{
+		{"journal.log", "\n  {\"path\":\"a\"}\n", index.FormatJournal},
+		{"index.bak", "<Objs></Objs>", index.FormatCLIXML},
+		{"index.clixml", "{}", index.FormatCLIXML},
+		{"x.ndjson", "<Objs/>", index.FormatJournal},
+		{"minified.idx", `{"algorithm":"SHA256","items":[{"path":"a"}]}`, index.FormatJSON},
+		{"array.idx", `[{"path":"a"}]`, index.FormatJSON},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(p, []byte(tt.content), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		got, err := index.DetectFormat(p)
+		if err != nil {
+			t.Fatalf("DetectFormat(%s): %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Fatalf("DetectFormat(%s): got %q want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// jsonIndex mirrors the object Write-ClixmlFromJournal exports, so the JSON
+// form reads the same as `Import-Clixml | ConvertTo-Json`.
+type jsonIndex struct {
+	CreatedUtc string        `json:"createdUtc,omitempty"`
+	StartedUtc string        `json:"startedUtc,omitempty"`
+	Algorithm  string        `json:"algorithm"`
+	Root       string        `json:"root"`
+	Total      int64         `json:"total"`
+	OkCount    int64         `json:"okCount"`
+	ErrorCount int64         `json:"errorCount"`
+	Items      []journalLine `json:"items"`
+}
+
+func OpenJSON(path string) (Source, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	// A bare array of items is accepted too, as ConvertTo-Json of the
+	// items alone produces one. It has no run members to check against.
+	var doc jsonIndex
+	var members map[string]json.RawMessage
+	data = bytes.TrimLeft(data, "\uFEFF \t\r\n")
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &doc.Items)
+	} else if err = json.Unmarshal(data, &doc); err == nil {
+		err = json.Unmarshal(data, &members)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sliceSource{run: RunInfo{Format: FormatJSON, Meta: map[string]any{}}}
+	for k, v := range map[string]any{
+		"algorithm":  doc.Algorithm,
+		"root":       doc.Root,
+		"total":      doc.Total,
+		"okCount":    doc.OkCount,
+		"errorCount": doc.ErrorCount,
+	} {
+		if _, ok := members[k]; ok {
+			s.run.setMember(k, v)
+		}
+	}
+	for k, v := range map[string]string{"createdUtc": doc.CreatedUtc, "startedUtc": doc.StartedUtc} {
+		if v != "" {
+			s.run.setMember(k, v)
+		}
+	}
+
+	s.items = make([]FileItem, 0, len(doc.Items))
+	for _, jl := range doc.Items {
+		s.items = append(s.items, jl.item())
+	}
+	s.sumBytes()
+	return s, nil
+}
+
+func WriteJSON(w io.Writer, run RunInfo, items []FileItem) error {
+	doc := jsonIndex{
+		CreatedUtc: formatTime(run.CreatedUtc),
+		StartedUtc: formatTime(run.StartedUtc),
+		Algorithm:  run.Algorithm,
+		Root:       run.Root,
+		Total:      int64(len(items)),
+		Items:      make([]journalLine, 0, len(items)),
+	}
+	for _, fi := range items {
+		if fi.Ok {
+			doc.OkCount++
+		}
+		doc.Items = append(doc.Items, newJournalLine(fi))
+	}
+	doc.ErrorCount = doc.Total - doc.OkCount
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(RoundTripLayout)
+}
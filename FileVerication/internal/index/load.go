@@ -2,142 +2,142 @@ package index
 
 import (
 	def "FileVerication/definitions"
-	"encoding/xml"
 	"fmt"
-	"os"
+	"math"
 	"strconv"
 	"strings"
 )
 
 func Load(path string) (run RunInfo, items []FileItem, err error) {
-	data, err := os.ReadFile(path) // #nosec G304
+	return LoadFormat(path, "")
+}
+
+// LoadFormat reads an index of a known format; an empty format is detected.
+func LoadFormat(path string, format Format) (run RunInfo, items []FileItem, err error) {
+	var r Source
+	if format == "" {
+		r, err = Open(path)
+	} else {
+		r, err = OpenFormat(path, format)
+	}
 	if err != nil {
 		return RunInfo{}, nil, err
 	}
+	defer func() {
+		_ = r.Close()
+	}()
 
-	var doc def.Objs
-	if err := xml.Unmarshal(data, &doc); err != nil {
-		return RunInfo{}, nil, err
-	}
-	if len(doc.Objects) == 0 {
-		return RunInfo{}, nil, fmt.Errorf("clixml: no top-level objects")
+	items = []FileItem{}
+	for fi, err := range r.All() {
+		if err != nil {
+			return RunInfo{}, nil, err
+		}
+		items = append(items, fi)
 	}
 
-	root := doc.Objects[0]
-	ms := root.MS
-
-	meta := map[string]any{}
-	for _, s := range ms.Strings {
-		meta[s.Name] = s.Value
-	}
-	for _, n := range ms.Int32s {
-		meta[n.Name] = n.Value
-	}
+	return r.Run(), items, nil
+}
 
-	var itemsObj *def.Obj
-	for i := range ms.Objs {
-		if ms.Objs[i].Name == "items" {
-			itemsObj = &ms.Objs[i]
-			break
+func itemFromDict(dct *def.Dict) (FileItem, error) {
+	var fi FileItem
+	for _, en := range dct.Entries {
+		k, v, ok, err := en.KeyValue()
+		if err != nil {
+			return FileItem{}, fmt.Errorf("clixml: entry decode error: %w", err)
 		}
-	}
-	if itemsObj == nil || itemsObj.LST == nil {
-		run = RunInfo{
-			Algorithm:  ms.Algorithm,
-			Meta:       meta,
-			TotalBytes: 0,
+		if !ok {
+			continue
 		}
-		return run, []FileItem{}, nil
-	}
 
-	toInt64 := func(v any) (int64, bool) {
-		switch n := v.(type) {
-		case int64:
-			return n, true
-		case int32:
-			return int64(n), true
-		case int:
-			return int64(n), true
-		case string:
-			parsed, err := strconv.ParseInt(n, 10, 64)
-			if err == nil {
-				return parsed, true
+		switch k {
+		case "ok":
+			if b, ok := toBool(v); ok {
+				fi.Ok = b
 			}
-		}
-		return 0, false
-	}
-
-	toBool := func(v any) (bool, bool) {
-		switch b := v.(type) {
-		case bool:
-			return b, true
-		case string:
-			switch strings.ToLower(strings.TrimSpace(b)) {
-			case "true", "1", "yes":
-				return true, true
-			case "false", "0", "no":
-				return false, true
-			default:
-				return false, false
+		case "path":
+			if s, ok := v.(string); ok {
+				fi.Path = s
 			}
-		}
-		return false, false
-	}
-
-	items = make([]FileItem, 0, len(itemsObj.LST.Items))
-	for _, item := range itemsObj.LST.Items {
-		if item.DCT == nil {
-			continue
-		}
-		var fi FileItem
-		for _, en := range item.DCT.Entries {
-			k, v, ok, err := en.KeyValue()
-			if err != nil {
-				return RunInfo{}, nil, fmt.Errorf("clixml: entry decode error: %w", err)
+		case "length":
+			if n, ok := toInt64(v); ok {
+				fi.Length = n
 			}
-			if !ok {
-				continue
+		case "hash":
+			if s, ok := v.(string); ok {
+				fi.Hash = s
 			}
-
-			switch k {
-			case "ok":
-				if b, ok := toBool(v); ok {
-					fi.Ok = b
-				}
-			case "path":
-				if s, ok := v.(string); ok {
-					fi.Path = s
-				}
-			case "length":
-				if n, ok := toInt64(v); ok {
-					fi.Length = n
-				}
-			case "hash":
-				if s, ok := v.(string); ok {
-					fi.Hash = s
-				}
-			case "error":
-				if v == nil {
-					fi.Error = nil
-				} else if s, ok := v.(string); ok {
-					fi.Error = &s
+		case "mtime":
+			fi.ModTime = toTime(v)
+		case "hashes":
+			if o, ok := v.(*def.Obj); ok && o.DCT != nil {
+				fi.Hashes = map[string]string{}
+				for _, hen := range o.DCT.Entries {
+					alg, h, ok, err := hen.KeyValue()
+					if err != nil {
+						return FileItem{}, fmt.Errorf("clixml: hashes entry decode error: %w", err)
+					}
+					if s, isString := h.(string); ok && isString {
+						fi.Hashes[AlgorithmKey(alg)] = s
+					}
 				}
 			}
+		case "error":
+			if v == nil {
+				fi.Error = nil
+			} else if s, ok := v.(string); ok {
+				fi.Error = &s
+			}
 		}
-		items = append(items, fi)
 	}
+	return fi, nil
+}
 
-	var totalBytes int64
-	for _, fi := range items {
-		if fi.Error == nil {
-			totalBytes += fi.Length
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), true
+		}
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<63 {
+			return int64(n), true
+		}
+	case string:
+		parsed, err := strconv.ParseInt(n, 10, 64)
+		if err == nil {
+			return parsed, true
 		}
 	}
+	return 0, false
+}
 
-	run = RunInfo{
-		Algorithm:  ms.Algorithm,
-		Meta:       meta,
-		TotalBytes: totalBytes,
+func toBool(v any) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "true", "1", "yes":
+			return true, true
+		case "false", "0", "no":
+			return false, true
+		default:
+			return false, false
+		}
 	}
-	return run, items, nil
+	return false, false
 }
@@ -188,3 +188,54 @@ func TestLoad_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_Hashtable(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "a.jpg")
+	if err := os.WriteFile(photo, []byte("12345"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	xml := `<Objs Version="1.1.0.1" xmlns="http://schemas.microsoft.com/powershell/2004/04">
+  <Obj RefId="0">
+    <TN RefId="0">
+      <T>System.Collections.Hashtable</T>
+      <T>System.Object</T>
+    </TN>
+    <DCT>
+      <En>
+        <S N="Key">` + photo + `</S>
+        <S N="Value">AAA</S>
+      </En>
+      <En>
+        <S N="Key">` + filepath.Join(dir, "missing.jpg") + `</S>
+        <S N="Value">BBB</S>
+      </En>
+    </DCT>
+  </Obj>
+</Objs>`
+
+	run, items, err := index.Load(writeTempCLIXML(t, xml))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if run.Format != index.FormatHashtable {
+		t.Fatalf("Format mismatch: got %q want %q", run.Format, index.FormatHashtable)
+	}
+	if run.Algorithm != "" {
+		t.Fatalf("expected no algorithm, got %q", run.Algorithm)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items length mismatch: got %d want 2", len(items))
+	}
+	if !items[0].Ok || items[0].Path != photo || items[0].Hash != "AAA" || items[0].Length != 0 {
+		t.Fatalf("item[0] mismatch: %+v", items[0])
+	}
+
+	if failed := index.FillLengths(items); failed != 1 {
+		t.Fatalf("FillLengths failed count: got %d want 1", failed)
+	}
+	if items[0].Length != 5 {
+		t.Fatalf("item[0] Length after fill: got %d want 5", items[0].Length)
+	}
+}
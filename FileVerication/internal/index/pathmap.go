@@ -0,0 +1,156 @@
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// PathMapping rewrites paths under From to the same relative path under To,
+// e.g. \\192.168.1.1\anime to /mnt/nas/anime.
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// PathMap is an ordered set of mappings. The longest matching From wins.
+// It implements flag.Value, taking one "from=to" rule per -map flag.
+type PathMap []PathMapping
+
+func (m *PathMap) String() string {
+	if m == nil {
+		return ""
+	}
+	rules := make([]string, 0, len(*m))
+	for _, pm := range *m {
+		rules = append(rules, pm.From+"="+pm.To)
+	}
+	return strings.Join(rules, ";")
+}
+
+func (m *PathMap) Set(rule string) error {
+	from, to, ok := strings.Cut(rule, "=")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !ok || from == "" || to == "" {
+		return fmt.Errorf("path mapping %q: want from=to", rule)
+	}
+	*m = append(*m, PathMapping{From: from, To: to})
+	return nil
+}
+
+// LoadPathMap reads mapping rules from a file, one "from=to" per line.
+// Blank lines and lines starting with # are ignored.
+func LoadPathMap(path string) (PathMap, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var m PathMap
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\uFEFF"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := m.Set(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return m, sc.Err()
+}
+
+// LoadFile appends the rules in the file at path, as LoadPathMap reads
+// them. An empty path loads nothing, so a -map-file flag can be passed
+// as is.
+func (m *PathMap) LoadFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	rules, err := LoadPathMap(path)
+	if err != nil {
+		return err
+	}
+	*m = append(*m, rules...)
+	return nil
+}
+
+// Resolve maps p to its local path. Prefixes match on whole path segments,
+// ignoring case and separator style. The remainder takes the separator style
+// of To, so a backslash UNC path maps onto a slash-separated mount.
+func (m PathMap) Resolve(p string) (string, bool) {
+	key := PathKey(p)
+	best, bestLen := -1, 0
+	for i, pm := range m {
+		from := strings.TrimRight(PathKey(pm.From), "/")
+		if key != from && !strings.HasPrefix(key, from+"/") {
+			continue
+		}
+		if best < 0 || len(from) > bestLen {
+			best, bestLen = i, len(from)
+		}
+	}
+	if best < 0 {
+		return p, false
+	}
+
+	// Lower-casing keeps the rune count, so the prefix is as many runes of p.
+	pm := m[best]
+	prefix := utf8.RuneCountInString(strings.TrimRight(PathKey(pm.From), "/"))
+	rest := p
+	for range prefix {
+		_, size := utf8.DecodeRuneInString(rest)
+		rest = rest[size:]
+	}
+	rest = strings.TrimLeft(rest, `\/`)
+	sep := "/"
+	if strings.Contains(pm.To, `\`) && !strings.Contains(pm.To, "/") {
+		sep = `\`
+	}
+	rest = strings.NewReplacer(`\`, sep, "/", sep).Replace(rest)
+	to := strings.TrimRight(pm.To, `\/`)
+	if rest == "" {
+		return to, true
+	}
+	return to + sep + rest, true
+}
+
+// Apply sets fi.LocalPath when a mapping matches.
+func (m PathMap) Apply(fi *FileItem) {
+	if local, ok := m.Resolve(fi.Path); ok {
+		fi.LocalPath = local
+	}
+}
+
+// MapSource wraps src so every item it yields carries its mapped LocalPath.
+func MapSource(src Source, m PathMap) Source {
+	if len(m) == 0 {
+		return src
+	}
+	return &mappedSource{Source: src, m: slices.Clone(m)}
+}
+
+type mappedSource struct {
+	Source
+	m PathMap
+}
+
+func (s *mappedSource) All() iter.Seq2[FileItem, error] {
+	return func(yield func(FileItem, error) bool) {
+		for fi, err := range s.Source.All() {
+			if err == nil {
+				s.m.Apply(&fi)
+			}
+			if !yield(fi, err) {
+				return
+			}
+		}
+	}
+}
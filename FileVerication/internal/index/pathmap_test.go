@@ -0,0 +1,80 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathMap_Resolve(t *testing.T) {
+	var m index.PathMap
+	for _, rule := range []string{
+		`\\192.168.1.1\anime=/mnt/nas/anime`,
+		`\\192.168.1.1\anime\Movies=/mnt/movies/`,
+		`/srv/share=D:\share`,
+		`\\nas\Ünïcode=/mnt/u`,
+	} {
+		if err := m.Set(rule); err != nil {
+			t.Fatalf("Set(%q): %v", rule, err)
+		}
+	}
+
+	tests := []struct {
+		in     string
+		want   string
+		mapped bool
+	}{
+		{`\\192.168.1.1\anime\Show\ep01.mkv`, "/mnt/nas/anime/Show/ep01.mkv", true},
+		{`\\192.168.1.1\ANIME\Show\ep01.mkv`, "/mnt/nas/anime/Show/ep01.mkv", true},
+		{`//192.168.1.1/anime/x.mkv`, "/mnt/nas/anime/x.mkv", true},
+		{`\\192.168.1.1\anime\Movies\m.mkv`, "/mnt/movies/m.mkv", true},
+		{`\\192.168.1.1\anime`, "/mnt/nas/anime", true},
+		{`\\192.168.1.1\animex\a.mkv`, `\\192.168.1.1\animex\a.mkv`, false},
+		{`/srv/share/a/b.mkv`, `D:\share\a\b.mkv`, true},
+		{`\\NAS\ÜNÏCODE\Ärger.mkv`, "/mnt/u/Ärger.mkv", true},
+	}
+	for _, tt := range tests {
+		got, ok := m.Resolve(tt.in)
+		if got != tt.want || ok != tt.mapped {
+			t.Fatalf("Resolve(%q): got %q %v want %q %v", tt.in, got, ok, tt.want, tt.mapped)
+		}
+	}
+
+	if err := m.Set("no-separator"); err == nil {
+		t.Fatalf("Set accepted a rule without '='")
+	}
+}
+
+func TestLoadPathMap_AppliesToSource(t *testing.T) {
+	dir := t.TempDir()
+	mapFile := filepath.Join(dir, "paths.map")
+	if err := os.WriteFile(mapFile, []byte("# share mounts\n\n\\\\nas\\anime = /mnt/anime\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	m, err := index.LoadPathMap(mapFile)
+	if err != nil {
+		t.Fatalf("LoadPathMap: %v", err)
+	}
+
+	journal := filepath.Join(dir, "j.ndjson")
+	if err := os.WriteFile(journal, []byte(`{"ok":true,"path":"\\\\nas\\anime\\a.mkv","length":1,"hash":"A","error":null}`+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	src, err := index.Open(journal)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	for fi, err := range index.MapSource(src, m).All() {
+		if err != nil {
+			t.Fatalf("All: %v", err)
+		}
+		if fi.Path != `\\nas\anime\a.mkv` || fi.LocalPath != "/mnt/anime/a.mkv" || fi.FSPath() != fi.LocalPath {
+			t.Fatalf("item mismatch: %+v", fi)
+		}
+	}
+}
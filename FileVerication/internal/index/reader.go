@@ -0,0 +1,368 @@
+package index
+
+import (
+	def "FileVerication/definitions"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+)
+
+// Reader parses a CLIXML index token by token. Open reads the run metadata up
+// to the items list; items are then decoded one at a time by Next or All, so
+// only a single item is held in memory at once.
+//
+// Both index shapes the scripts write are understood: the run object from
+// Write-ClixmlFromJournal and the flat path->hash hashtable that
+// Build-ExistingFilesIndex-Parallel.ps1 exports. The latter carries no
+// algorithm or lengths; see FillLength.
+//
+// Items PowerShell deduplicated into <Ref RefId="n"/> are re-read from the
+// offset of the object they point to, so only offsets are kept in memory.
+type Reader struct {
+	f *os.File
+	d *xml.Decoder
+
+	offsets   map[int]int64
+	run       RunInfo
+	inItems   bool
+	inDict    bool
+	seenItems bool
+	done      bool
+}
+
+func OpenCLIXML(path string) (*Reader, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{
+		f:       f,
+		d:       xml.NewDecoder(bufio.NewReaderSize(f, 1<<20)),
+		offsets: map[int]int64{},
+		run:     RunInfo{Format: FormatCLIXML, Meta: map[string]any{}},
+	}
+	if err := r.start(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run returns the metadata read so far. Members that follow the items list
+// and TotalBytes are only complete once Next has returned io.EOF.
+func (r *Reader) Run() RunInfo {
+	return r.run
+}
+
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+// Next returns the next item, or io.EOF once the items list and the rest of
+// the root object have been consumed.
+func (r *Reader) Next() (FileItem, error) {
+	if r.inDict {
+		return r.nextEntry()
+	}
+
+	for r.inItems {
+		offset := r.d.InputOffset()
+		tok, err := r.token()
+		if err != nil {
+			return FileItem{}, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var o def.Obj
+			switch t.Name.Local {
+			case "Obj":
+				if err := r.d.DecodeElement(&o, &t); err != nil {
+					return FileItem{}, err
+				}
+				r.offsets[o.RefID] = offset
+			case "Ref":
+				if err := r.d.Skip(); err != nil {
+					return FileItem{}, err
+				}
+				if o, err = r.resolve(attr(t, "RefId")); err != nil {
+					return FileItem{}, err
+				}
+			default:
+				if err := r.d.Skip(); err != nil {
+					return FileItem{}, err
+				}
+				continue
+			}
+
+			if o.DCT == nil {
+				continue
+			}
+			fi, err := itemFromDict(o.DCT)
+			if err != nil {
+				return FileItem{}, err
+			}
+			if fi.Error == nil {
+				r.run.TotalBytes += fi.Length
+			}
+			return fi, nil
+		case xml.EndElement:
+			// </LST>: leave the items object and pick up any members after it.
+			r.inItems = false
+			if err := r.d.Skip(); err != nil {
+				return FileItem{}, err
+			}
+			if err := r.readMembers(); err != nil {
+				return FileItem{}, err
+			}
+			if err := r.finish(); err != nil {
+				return FileItem{}, err
+			}
+		}
+	}
+	return FileItem{}, io.EOF
+}
+
+// resolve re-decodes the item object a <Ref> points to.
+func (r *Reader) resolve(refID string) (def.Obj, error) {
+	id, err := strconv.Atoi(refID)
+	if err != nil {
+		return def.Obj{}, fmt.Errorf("clixml: invalid RefId %q", refID)
+	}
+	offset, ok := r.offsets[id]
+	if !ok {
+		return def.Obj{}, fmt.Errorf("clixml: reference to unknown item RefId %d", id)
+	}
+
+	d := xml.NewDecoder(io.NewSectionReader(r.f, offset, 1<<62))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return def.Obj{}, fmt.Errorf("clixml: resolve RefId %d: %w", id, err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			var o def.Obj
+			err := d.DecodeElement(&o, &se)
+			return o, err
+		}
+	}
+}
+
+// nextEntry decodes one path->hash pair of a flat hashtable index.
+func (r *Reader) nextEntry() (FileItem, error) {
+	for r.inDict {
+		tok, err := r.token()
+		if err != nil {
+			return FileItem{}, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var en def.En
+			if err := r.d.DecodeElement(&en, &t); err != nil {
+				return FileItem{}, err
+			}
+			k, v, ok, err := en.KeyValue()
+			if err != nil {
+				return FileItem{}, fmt.Errorf("clixml: entry decode error: %w", err)
+			}
+			hash, isString := v.(string)
+			if !ok || !isString || k == "" || hash == "" {
+				continue
+			}
+			return FileItem{Ok: true, Path: k, Hash: hash}, nil
+		case xml.EndElement:
+			// </DCT>
+			r.inDict = false
+			if err := r.finish(); err != nil {
+				return FileItem{}, err
+			}
+		}
+	}
+	return FileItem{}, io.EOF
+}
+
+// All yields every remaining item. Iteration stops after the first error.
+func (r *Reader) All() iter.Seq2[FileItem, error] {
+	return func(yield func(FileItem, error) bool) {
+		for {
+			fi, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(fi, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *Reader) token() (xml.Token, error) {
+	tok, err := r.d.Token()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("clixml: %w", io.ErrUnexpectedEOF)
+	}
+	return tok, err
+}
+
+// start advances to the first top-level <Obj> and reads its members.
+func (r *Reader) start() error {
+	for {
+		tok, err := r.d.Token()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("clixml: no top-level objects")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Objs" {
+				continue
+			}
+			if t.Name.Local != "Obj" {
+				if err := r.d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			return r.readRoot()
+		case xml.EndElement:
+			if t.Name.Local == "Objs" {
+				return fmt.Errorf("clixml: no top-level objects")
+			}
+		}
+	}
+}
+
+func (r *Reader) readRoot() error {
+	for {
+		tok, err := r.token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "DCT" {
+				r.run.Format = FormatHashtable
+				r.inDict = true
+				return nil
+			}
+			if t.Name.Local != "MS" {
+				if err := r.d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.readMembers(); err != nil {
+				return err
+			}
+			if r.inItems {
+				return nil
+			}
+		case xml.EndElement:
+			r.done = true
+			return nil
+		}
+	}
+}
+
+// readMembers consumes <MS> children until </MS>, or pauses on the items list
+// with inItems set.
+func (r *Reader) readMembers() error {
+	for {
+		tok, err := r.token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Obj":
+				if r.seenItems || attr(t, "N") != "items" {
+					if err := r.d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				r.seenItems = true
+				found, err := r.enterList()
+				if err != nil {
+					return err
+				}
+				if found {
+					r.inItems = true
+					return nil
+				}
+			default:
+				if !def.IsPrimitive(t.Name.Local) {
+					if err := r.d.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				var text string
+				if err := r.d.DecodeElement(&text, &t); err != nil {
+					return err
+				}
+				v, err := def.DecodePrimitive(t.Name.Local, text)
+				if err != nil {
+					return fmt.Errorf("clixml: member %q: %w", attr(t, "N"), err)
+				}
+				r.run.setMember(attr(t, "N"), v)
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// enterList advances into the <LST> of the items object. It reports false,
+// with the object fully consumed, when the object has no list.
+func (r *Reader) enterList() (bool, error) {
+	for {
+		tok, err := r.token()
+		if err != nil {
+			return false, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "LST" {
+				return true, nil
+			}
+			if err := r.d.Skip(); err != nil {
+				return false, err
+			}
+		case xml.EndElement:
+			return false, nil
+		}
+	}
+}
+
+// finish consumes the remainder of the root object after </MS> or </DCT>.
+func (r *Reader) finish() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	return r.d.Skip()
+}
+
+func attr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const xmlItemsBeforeMeta = `<?xml version="1.0" encoding="utf-8"?>
+<Objs Version="1.1.0.1" xmlns="http://schemas.microsoft.com/powershell/2004/04">
+  <Obj RefId="0">
+    <TN RefId="0"><T>System.Management.Automation.PSCustomObject</T></TN>
+    <MS>
+      <S N="algorithm">SHA256</S>
+      <Obj N="other" RefId="1"><LST><Obj RefId="9"><DCT /></Obj></LST></Obj>
+      <Obj N="items" RefId="2">
+        <TN RefId="1"><T>System.Object[]</T></TN>
+        <LST>
+          <Obj RefId="3">
+            <DCT>
+              <En><S N="Key">ok</S><B N="Value">true</B></En>
+              <En><S N="Key">path</S><S N="Value">a.mkv</S></En>
+              <En><S N="Key">length</S><I64 N="Value">10</I64></En>
+              <En><S N="Key">hash</S><S N="Value">AAA</S></En>
+              <En><S N="Key">error</S><Nil N="Value" /></En>
+            </DCT>
+          </Obj>
+          <Obj RefId="4">
+            <TNRef RefId="1" />
+            <DCT>
+              <En><S N="Key">ok</S><B N="Value">true</B></En>
+              <En><S N="Key">path</S><S N="Value">b.mkv</S></En>
+              <En><S N="Key">length</S><I64 N="Value">5</I64></En>
+              <En><S N="Key">hash</S><S N="Value">BBB</S></En>
+              <En><S N="Key">error</S><Nil N="Value" /></En>
+            </DCT>
+          </Obj>
+        </LST>
+      </Obj>
+      <S N="root">\\server\share</S>
+      <I32 N="total">2</I32>
+    </MS>
+  </Obj>
+</Objs>`
+
+func TestReader_Streams(t *testing.T) {
+	p := writeTempCLIXML(t, xmlItemsBeforeMeta)
+
+	r, err := index.OpenCLIXML(p)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	if got := r.Run().Algorithm; got != "SHA256" {
+		t.Fatalf("Algorithm before items: got %q want %q", got, "SHA256")
+	}
+
+	var paths []string
+	for fi, err := range r.All() {
+		if err != nil {
+			t.Fatalf("All: %v", err)
+		}
+		paths = append(paths, fi.Path)
+	}
+	if strings.Join(paths, ",") != "a.mkv,b.mkv" {
+		t.Fatalf("paths mismatch: got %v", paths)
+	}
+
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Next after end: got %v want io.EOF", err)
+	}
+
+	run := r.Run()
+	if run.Meta["root"] != `\\server\share` || run.Meta["total"] != int32(2) {
+		t.Fatalf("members after items not read: %#v", run.Meta)
+	}
+	if run.TotalBytes != 15 {
+		t.Fatalf("TotalBytes mismatch: got %d want %d", run.TotalBytes, 15)
+	}
+}
+
+func TestReader_Truncated(t *testing.T) {
+	cut := strings.Index(xmlItemsBeforeMeta, `<Obj RefId="4">`)
+	p := writeTempCLIXML(t, xmlItemsBeforeMeta[:cut+20])
+
+	r, err := index.OpenCLIXML(p)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("first item: %v", err)
+	}
+	if _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Fatalf("expected decode error on truncated file, got %v", err)
+	}
+}
+
+func TestReader_RefsAndTypedMembers(t *testing.T) {
+	const doc = `<Objs Version="1.1.0.1" xmlns="http://schemas.microsoft.com/powershell/2004/04">
+  <Obj RefId="0">
+    <MS>
+      <DT N="createdUtc">2026-02-16T23:09:08.4209857Z</DT>
+      <S N="algorithm">SHA1</S>
+      <I64 N="total">3</I64>
+      <Obj N="items" RefId="1">
+        <LST>
+          <Obj RefId="2">
+            <DCT>
+              <En><S N="Key">ok</S><B N="Value">true</B></En>
+              <En><S N="Key">path</S><S N="Value">a.mkv</S></En>
+              <En><S N="Key">length</S><U64 N="Value">10</U64></En>
+              <En><S N="Key">hash</S><S N="Value">AAA</S></En>
+            </DCT>
+          </Obj>
+          <Obj RefId="3">
+            <DCT>
+              <En><S N="Key">ok</S><B N="Value">true</B></En>
+              <En><S N="Key">path</S><S N="Value">b.mkv</S></En>
+              <En><S N="Key">length</S><I32 N="Value">4</I32></En>
+              <En><S N="Key">hash</S><S N="Value">BBB</S></En>
+            </DCT>
+          </Obj>
+          <Ref RefId="2" />
+        </LST>
+      </Obj>
+    </MS>
+  </Obj>
+</Objs>`
+
+	run, items, err := index.Load(writeTempCLIXML(t, doc))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !run.CreatedUtc.Equal(time.Date(2026, 2, 16, 23, 9, 8, 420985700, time.UTC)) || run.Total != 3 {
+		t.Fatalf("typed members not applied: created=%v total=%d", run.CreatedUtc, run.Total)
+	}
+
+	var paths []string
+	for _, fi := range items {
+		paths = append(paths, fi.Path)
+	}
+	if strings.Join(paths, ",") != "a.mkv,b.mkv,a.mkv" {
+		t.Fatalf("paths mismatch: got %v", paths)
+	}
+	if items[2].Length != 10 || items[2].Hash != "AAA" {
+		t.Fatalf("Ref item mismatch: %+v", items[2])
+	}
+
+	problems := index.Validate(run, items)
+	if len(problems) != 1 || problems[0].Kind != index.ProblemDuplicate {
+		t.Fatalf("expected one duplicate problem, got %v", problems)
+	}
+}
@@ -0,0 +1,168 @@
+package index
+
+import (
+	def "FileVerication/definitions"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// RoundTripLayout is .NET's "o" format, which Write-ClixmlFromJournal uses for
+// createdUtc and startedUtc.
+const RoundTripLayout = "2006-01-02T15:04:05.0000000Z07:00"
+
+// Save writes items as a CLIXML index in the shape Write-ClixmlFromJournal
+// produces, so the result can be read by Import-Clixml as well as Load.
+func Save(path string, run RunInfo, items []FileItem) error {
+	return SaveFormat(path, FormatCLIXML, run, items)
+}
+
+// SaveFormat writes an index in the given format. The file is written next
+// to path and renamed over it once fully synced.
+func SaveFormat(path string, format Format, run RunInfo, items []FileItem) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, run, items); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("%s: encode %s: %w", format, filepath.Base(path), err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Encode converts an index into the definitions tree Save writes out.
+func Encode(run RunInfo, items []FileItem) def.Objs {
+	var okCount, errCount int32
+	for _, fi := range items {
+		if fi.Ok {
+			okCount++
+		} else {
+			errCount++
+		}
+	}
+
+	createdUtc := run.CreatedUtc
+	if createdUtc.IsZero() {
+		createdUtc = time.Now()
+	}
+	startedUtc := run.StartedUtc
+	if startedUtc.IsZero() {
+		startedUtc = createdUtc
+	}
+
+	list := &def.List{Items: make([]def.Obj, 0, len(items))}
+	nextRef := len(items) + 2
+	hashtableTN := false
+	for i, fi := range items {
+		// Objects are numbered after the root (0) and the items array (1); the
+		// dictionary type name is declared once and referenced afterwards.
+		o := def.Obj{RefID: i + 2}
+		if i == 0 {
+			o.TN = &def.TypeNames{RefID: 2, Names: []string{
+				"System.Collections.Specialized.OrderedDictionary",
+				"System.Object",
+			}}
+		} else {
+			o.TNRef = &def.TypeNameRef{RefID: 2}
+		}
+
+		var hash any
+		if fi.Hash != "" {
+			hash = fi.Hash
+		}
+		o.DCT = &def.Dict{Entries: []def.En{
+			def.NewEntry("ok", fi.Ok),
+			def.NewEntry("path", fi.Path),
+			def.NewEntry("length", fi.Length),
+			def.NewEntry("hash", hash),
+			def.NewEntry("error", fi.Error),
+		}}
+		if !fi.ModTime.IsZero() {
+			o.DCT.Entries = append(o.DCT.Entries, def.NewEntry("mtime", fi.ModTime.UTC()))
+		}
+		if len(fi.Hashes) > 0 {
+			// Extra digests are a nested Hashtable, numbered after the items.
+			h := &def.Obj{RefID: nextRef, DCT: &def.Dict{}}
+			nextRef++
+			if !hashtableTN {
+				h.TN = &def.TypeNames{RefID: 3, Names: []string{"System.Collections.Hashtable", "System.Object"}}
+				hashtableTN = true
+			} else {
+				h.TNRef = &def.TypeNameRef{RefID: 3}
+			}
+			for _, alg := range slices.Sorted(maps.Keys(fi.Hashes)) {
+				h.DCT.Entries = append(h.DCT.Entries, def.NewEntry(alg, fi.Hashes[alg]))
+			}
+			o.DCT.Entries = append(o.DCT.Entries, def.NewEntry("hashes", h))
+		}
+		list.Items = append(list.Items, o)
+	}
+
+	root := def.Obj{
+		RefID: 0,
+		TN: &def.TypeNames{RefID: 0, Names: []string{
+			"System.Management.Automation.PSCustomObject",
+			"System.Object",
+		}},
+		MS: &def.Member{
+			Strings: []def.NamedString{
+				{Name: "createdUtc", Value: createdUtc.UTC().Format(RoundTripLayout)},
+				{Name: "startedUtc", Value: startedUtc.UTC().Format(RoundTripLayout)},
+				{Name: "algorithm", Value: run.Algorithm},
+				{Name: "root", Value: run.Root},
+			},
+			Int32s: []def.NamedInt32{
+				{Name: "total", Value: int32(len(items))}, // #nosec G115 -- PowerShell stores the count as Int32
+				{Name: "okCount", Value: okCount},
+				{Name: "errorCount", Value: errCount},
+			},
+			Objs: []def.Obj{{
+				Name:  "items",
+				RefID: 1,
+				TN: &def.TypeNames{RefID: 1, Names: []string{
+					"System.Object[]",
+					"System.Array",
+					"System.Object",
+				}},
+				LST: list,
+			}},
+		},
+	}
+
+	return def.Objs{Objects: []def.Obj{root}}
+}
+
+// EncodeHashtable converts an index into the flat path -> hash Hashtable of
+// ExistingFilesIndex.clixml. Only items that hashed successfully are kept.
+func EncodeHashtable(items []FileItem) def.Objs {
+	dict := &def.Dict{}
+	for _, fi := range items {
+		if fi.Ok && fi.Hash != "" {
+			dict.Entries = append(dict.Entries, def.NewEntry(fi.Path, fi.Hash))
+		}
+	}
+	root := def.Obj{
+		RefID: 0,
+		TN: &def.TypeNames{RefID: 0, Names: []string{
+			"System.Collections.Hashtable",
+			"System.Object",
+		}},
+		DCT: dict,
+	}
+	return def.Objs{Objects: []def.Obj{root}}
+}
@@ -0,0 +1,91 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSave_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "out.clixml")
+
+	run := index.RunInfo{
+		Algorithm:  "SHA256",
+		Root:       `\\192.168.1.1\anime`,
+		CreatedUtc: time.Date(2026, 2, 16, 23, 9, 8, 420985700, time.UTC),
+		StartedUtc: time.Date(2026, 2, 16, 23, 8, 14, 611093900, time.UTC),
+	}
+	items := []index.FileItem{
+		{Ok: true, Path: `\\192.168.1.1\anime\a.mkv`, Length: 10, Hash: "AAA"},
+		{Ok: false, Path: `\\192.168.1.1\anime\b <&> "c".mkv`, Length: 20, Error: strPtr("access denied")},
+		{Ok: true, Path: `\\192.168.1.1\anime\odd_x0041_name` + "\u00e9\u3042.mkv", Length: 30, Hash: "CCC"},
+	}
+
+	if err := index.Save(p, run, items); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(p + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file left behind: %v", err)
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, want := range []string{
+		`<Objs Version="1.1.0.1" xmlns="http://schemas.microsoft.com/powershell/2004/04">`,
+		`<I32 N="total">3</I32>`,
+		`<I32 N="okCount">2</I32>`,
+		`<TNRef RefId="2"></TNRef>`,
+		`<I64 N="Value">20</I64>`,
+		`<Nil N="Value"></Nil>`,
+		`odd_x005F_x0041_name`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("output missing %q:\n%s", want, data)
+		}
+	}
+
+	gotRun, gotItems, err := index.Load(p)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if gotRun.Algorithm != run.Algorithm || gotRun.Root != run.Root {
+		t.Fatalf("run mismatch: got %q %q want %q %q", gotRun.Algorithm, gotRun.Root, run.Algorithm, run.Root)
+	}
+	if !gotRun.CreatedUtc.Equal(run.CreatedUtc) || !gotRun.StartedUtc.Equal(run.StartedUtc) {
+		t.Fatalf("times mismatch: got %v %v want %v %v", gotRun.CreatedUtc, gotRun.StartedUtc, run.CreatedUtc, run.StartedUtc)
+	}
+	if gotRun.Total != 3 || gotRun.OkCount != 2 || gotRun.ErrorCount != 1 {
+		t.Fatalf("counts mismatch: total=%d ok=%d err=%d", gotRun.Total, gotRun.OkCount, gotRun.ErrorCount)
+	}
+	if got := gotRun.Meta["createdUtc"]; got != "2026-02-16T23:09:08.4209857Z" {
+		t.Fatalf("Meta[createdUtc] mismatch: got %#v", got)
+	}
+	if problems := index.Validate(gotRun, gotItems); len(problems) != 0 {
+		t.Fatalf("round-tripped index has problems: %v", problems)
+	}
+	if gotRun.TotalBytes != 40 {
+		t.Fatalf("TotalBytes mismatch: got %d want %d", gotRun.TotalBytes, 40)
+	}
+
+	if len(gotItems) != len(items) {
+		t.Fatalf("items length mismatch: got %d want %d", len(gotItems), len(items))
+	}
+	for i := range items {
+		got, want := gotItems[i], items[i]
+		if got.Ok != want.Ok || got.Path != want.Path || got.Length != want.Length || got.Hash != want.Hash {
+			t.Fatalf("item[%d] mismatch:\n got:  %+v\n want: %+v", i, got, want)
+		}
+		if (got.Error == nil) != (want.Error == nil) {
+			t.Fatalf("item[%d] Error nil mismatch: got=%v want=%v", i, got.Error == nil, want.Error == nil)
+		}
+		if got.Error != nil && *got.Error != *want.Error {
+			t.Fatalf("item[%d] Error mismatch: got=%q want=%q", i, *got.Error, *want.Error)
+		}
+	}
+}
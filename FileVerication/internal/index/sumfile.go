@@ -0,0 +1,155 @@
+package index
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OpenSumFile reads GNU coreutils checksum output ("sha256sum" and friends):
+// one "<hex>  <name>" line per file, with the backslash-prefixed escaping
+// coreutils uses for names containing '\' or newlines. The format has no
+// lengths or errors, so every item is Ok with Length 0; see FillLength. The
+// algorithm is taken from the extension (.sha256, .md5, ...) or digest size.
+func OpenSumFile(path string) (Source, error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	s := &sliceSource{run: RunInfo{Format: FormatSumFile, Meta: map[string]any{}}}
+	digestLen := 0
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		escaped := strings.HasPrefix(line, `\`)
+		if escaped {
+			line = line[1:]
+		}
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok || len(name) < 2 || (name[0] != ' ' && name[0] != '*') || !isHex(hash) {
+			s.run.Malformed++
+			continue
+		}
+		name = name[1:]
+		if escaped {
+			name = unescapeSumName(name)
+		}
+
+		if digestLen == 0 {
+			digestLen = len(hash)
+		}
+		s.items = append(s.items, FileItem{Ok: true, Path: name, Hash: strings.ToUpper(hash)})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	alg := algorithmFromExt(path)
+	if alg == "" {
+		alg = algorithmFromDigestLen(digestLen)
+	}
+	if alg != "" {
+		s.run.setMember("algorithm", alg)
+	}
+	return s, nil
+}
+
+// WriteSumFile writes the ok items in GNU checksum format with lower-case
+// digests, so `sha256sum -c` can check them. Items without a hash are
+// omitted, as the format cannot carry errors.
+func WriteSumFile(w io.Writer, items []FileItem) error {
+	bw := bufio.NewWriter(w)
+	for _, fi := range items {
+		if !fi.Ok || fi.Hash == "" {
+			continue
+		}
+		name, escaped := escapeSumName(fi.Path)
+		prefix := ""
+		if escaped {
+			prefix = `\`
+		}
+		if _, err := fmt.Fprintf(bw, "%s%s  %s\n", prefix, strings.ToLower(fi.Hash), name); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func escapeSumName(name string) (string, bool) {
+	if !strings.ContainsAny(name, "\\\n\r") {
+		return name, false
+	}
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	return r.Replace(name), true
+}
+
+func unescapeSumName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '\\' || i+1 == len(name) {
+			b.WriteByte(name[i])
+			continue
+		}
+		i++
+		switch name[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(name[i])
+		}
+	}
+	return b.String()
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func algorithmFromExt(path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	ext = strings.TrimSuffix(ext, "sum")
+	switch ext {
+	case "sha256", "sha1", "sha512", "sha384", "md5":
+		return strings.ToUpper(ext)
+	}
+	return ""
+}
+
+func algorithmFromDigestLen(n int) string {
+	switch n {
+	case 32:
+		return "MD5"
+	case 40:
+		return "SHA1"
+	case 64:
+		return "SHA256"
+	case 96:
+		return "SHA384"
+	case 128:
+		return "SHA512"
+	}
+	return ""
+}
@@ -1,15 +1,45 @@
 package index
 
+import (
+	"FileVerication/internal/hashalg"
+	"time"
+)
+
+type Format string
+
+const (
+	// FormatCLIXML is the run object written by Write-ClixmlFromJournal.
+	FormatCLIXML Format = "clixml"
+	// FormatHashtable is the flat path->hash ExistingFilesIndex.clixml.
+	FormatHashtable Format = "clixml-hashtable"
+	// FormatJournal is the AnimeHashIndex.journal.ndjson resume journal.
+	FormatJournal Format = "ndjson"
+	// FormatJSON is the run object as a single JSON document.
+	FormatJSON Format = "json"
+	// FormatCSV has one ok,path,length,hash,error row per item.
+	FormatCSV Format = "csv"
+	// FormatSumFile is GNU coreutils checksum output, as sha256sum writes it.
+	FormatSumFile Format = "sha256sum"
+)
+
+var Formats = []Format{FormatCLIXML, FormatHashtable, FormatJournal, FormatJSON, FormatCSV, FormatSumFile}
+
 type RunInfo struct {
+	Format     Format
 	Algorithm  string
 	Meta       map[string]any
 	Total      int64
 	OkCount    int64
 	ErrorCount int64
 	Root       string
-	CreatedUtc string
-	StartedUtc string
+	CreatedUtc time.Time
+	StartedUtc time.Time
 	TotalBytes int64
+
+	// Malformed and Duplicates count journal lines that were skipped or
+	// superseded by a later line for the same path.
+	Malformed  int64
+	Duplicates int64
 }
 
 type FileItem struct {
@@ -18,4 +48,75 @@ type FileItem struct {
 	Length int64
 	Hash   string
 	Error  *string
+	// Hashes holds digests by further algorithms, keyed by AlgorithmKey;
+	// the digest by the run's Algorithm stays in Hash.
+	Hashes map[string]string
+	// ModTime is the file's modification time when it was hashed; zero for
+	// indexes written before it was recorded.
+	ModTime time.Time
+	// LocalPath is where the file is found on this machine when a PathMap
+	// rewrote Path; it is never written back to an index.
+	LocalPath string
+}
+
+// FSPath is the path to open the file at: LocalPath if set, else Path.
+func (fi FileItem) FSPath() string {
+	if fi.LocalPath != "" {
+		return fi.LocalPath
+	}
+	return fi.Path
+}
+
+// Digests returns every digest recorded for fi, with Hash under algorithm.
+func (fi FileItem) Digests(algorithm string) map[string]string {
+	d := make(map[string]string, len(fi.Hashes)+1)
+	for alg, h := range fi.Hashes {
+		d[AlgorithmKey(alg)] = h
+	}
+	if fi.Hash != "" {
+		d[AlgorithmKey(algorithm)] = fi.Hash
+	}
+	return d
+}
+
+// AlgorithmKey is the form algorithm names are compared and stored in.
+func AlgorithmKey(algorithm string) string {
+	return hashalg.Key(algorithm)
+}
+
+// setMember records a member of the index object in Meta and, for the
+// members Write-ClixmlFromJournal writes, in the matching typed field.
+// Timestamps that don't parse stay zero; Validate reports them.
+func (run *RunInfo) setMember(name string, value any) {
+	run.Meta[name] = value
+
+	switch name {
+	case "algorithm":
+		run.Algorithm, _ = value.(string)
+	case "root":
+		run.Root, _ = value.(string)
+	case "createdUtc":
+		run.CreatedUtc = toTime(value)
+	case "startedUtc":
+		run.StartedUtc = toTime(value)
+	case "total":
+		run.Total, _ = toInt64(value)
+	case "okCount":
+		run.OkCount, _ = toInt64(value)
+	case "errorCount":
+		run.ErrorCount, _ = toInt64(value)
+	}
+}
+
+// toTime accepts both the ISO strings the indexer writes and typed <DT>
+// members.
+func toTime(v any) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case string:
+		parsed, _ := time.Parse(time.RFC3339Nano, t)
+		return parsed
+	}
+	return time.Time{}
 }
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"FileVerication/internal/hashalg"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ProblemKind string
+
+const (
+	ProblemTotal        ProblemKind = "total_mismatch"
+	ProblemOkCount      ProblemKind = "ok_count_mismatch"
+	ProblemErrorCount   ProblemKind = "error_count_mismatch"
+	ProblemTimestamp    ProblemKind = "bad_timestamp"
+	ProblemOutsideRoot  ProblemKind = "outside_root"
+	ProblemDuplicate    ProblemKind = "duplicate_path"
+	ProblemEmptyHash    ProblemKind = "empty_hash"
+	ProblemMissingPath  ProblemKind = "missing_path"
+	ProblemNegativeSize ProblemKind = "negative_length"
+	ProblemAlgorithm    ProblemKind = "unknown_algorithm"
+)
+
+type Problem struct {
+	Kind   ProblemKind
+	Path   string
+	Detail string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return fmt.Sprintf("%s: %s", p.Kind, p.Detail)
+	}
+	if p.Detail == "" {
+		return fmt.Sprintf("%s: %s", p.Kind, p.Path)
+	}
+	return fmt.Sprintf("%s: %s (%s)", p.Kind, p.Path, p.Detail)
+}
+
+// Validator checks an index for self-consistency while its items stream by.
+// Paths are compared the way the Windows indexer builds them: separators
+// normalized and case ignored.
+type Validator struct {
+	root     string
+	alg      string
+	algs     map[string]struct{}
+	seen     map[string]struct{}
+	count    int64
+	ok       int64
+	problems []Problem
+}
+
+// NewValidator starts a validation pass. run only needs the members that
+// precede the items list; Finish takes the final RunInfo.
+func NewValidator(run RunInfo) *Validator {
+	v := &Validator{alg: run.Algorithm, algs: map[string]struct{}{}, seen: map[string]struct{}{}}
+	if run.Root != "" {
+		v.root = strings.TrimRight(PathKey(run.Root), "/") + "/"
+	}
+	return v
+}
+
+func (v *Validator) Add(fi FileItem) {
+	v.count++
+	if fi.Ok {
+		v.ok++
+	}
+
+	if fi.Path == "" {
+		v.problems = append(v.problems, Problem{Kind: ProblemMissingPath, Detail: fmt.Sprintf("item %d", v.count)})
+		return
+	}
+
+	key := PathKey(fi.Path)
+	if _, dup := v.seen[key]; dup {
+		v.problems = append(v.problems, Problem{Kind: ProblemDuplicate, Path: fi.Path})
+	} else {
+		v.seen[key] = struct{}{}
+	}
+
+	if v.root != "" && !strings.HasPrefix(key, v.root) {
+		v.problems = append(v.problems, Problem{Kind: ProblemOutsideRoot, Path: fi.Path})
+	}
+	if fi.Ok && strings.TrimSpace(fi.Hash) == "" {
+		v.problems = append(v.problems, Problem{Kind: ProblemEmptyHash, Path: fi.Path})
+	}
+	if fi.Length < 0 {
+		v.problems = append(v.problems, Problem{Kind: ProblemNegativeSize, Path: fi.Path, Detail: fmt.Sprint(fi.Length)})
+	}
+	for alg := range fi.Hashes {
+		if _, checked := v.algs[alg]; checked {
+			continue
+		}
+		v.algs[alg] = struct{}{}
+		if _, ok := hashalg.Lookup(alg); !ok {
+			v.problems = append(v.problems, Problem{Kind: ProblemAlgorithm, Path: fi.Path, Detail: unknownAlgorithm(alg)})
+		}
+	}
+}
+
+func unknownAlgorithm(alg string) string {
+	return fmt.Sprintf("%q is not one of %s", alg, strings.Join(hashalg.Names(), ", "))
+}
+
+// Finish compares the declared counts in run with what was seen and returns
+// every problem found. Counts are only checked when the index declares them.
+func (v *Validator) Finish(run RunInfo) []Problem {
+	var head []Problem
+	check := func(kind ProblemKind, member string, declared, actual int64) {
+		if _, ok := run.Meta[member]; ok && declared != actual {
+			head = append(head, Problem{Kind: kind, Detail: fmt.Sprintf("%s=%d but index has %d", member, declared, actual)})
+		}
+	}
+	if v.alg != "" {
+		if _, ok := hashalg.Lookup(v.alg); !ok {
+			head = append(head, Problem{Kind: ProblemAlgorithm, Detail: "algorithm " + unknownAlgorithm(v.alg)})
+		}
+	}
+	check(ProblemTotal, "total", run.Total, v.count)
+	check(ProblemOkCount, "okCount", run.OkCount, v.ok)
+	check(ProblemErrorCount, "errorCount", run.ErrorCount, v.count-v.ok)
+
+	for _, member := range []string{"createdUtc", "startedUtc"} {
+		s, ok := run.Meta[member].(string)
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			head = append(head, Problem{Kind: ProblemTimestamp, Detail: fmt.Sprintf("%s=%q", member, s)})
+		}
+	}
+
+	return append(head, v.problems...)
+}
+
+// Validate runs a Validator over a fully loaded index.
+func Validate(run RunInfo, items []FileItem) []Problem {
+	v := NewValidator(run)
+	for _, fi := range items {
+		v.Add(fi)
+	}
+	return v.Finish(run)
+}
+
+// PathKey normalizes a path for comparison the way the Windows indexer
+// treats paths: separators unified and case ignored.
+func PathKey(p string) string {
+	return strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+}
@@ -0,0 +1,91 @@
+package index_test
+
+import (
+	"FileVerication/internal/index"
+	"testing"
+)
+
+func TestValidate_TableDriven(t *testing.T) {
+	declared := func(total, ok, errs int32) index.RunInfo {
+		return index.RunInfo{
+			Root:       `\\nas\anime`,
+			Total:      int64(total),
+			OkCount:    int64(ok),
+			ErrorCount: int64(errs),
+			Meta: map[string]any{
+				"root":       `\\nas\anime`,
+				"total":      total,
+				"okCount":    ok,
+				"errorCount": errs,
+				"createdUtc": "2026-02-16T23:09:08.4209857Z",
+			},
+		}
+	}
+
+	good := []index.FileItem{
+		{Ok: true, Path: `\\nas\anime\a.mkv`, Length: 1, Hash: "AAA"},
+		{Ok: false, Path: `\\nas\anime\b.mkv`, Error: strPtr("denied")},
+	}
+
+	tests := []struct {
+		name  string
+		run   index.RunInfo
+		items []index.FileItem
+		want  []index.ProblemKind
+	}{
+		{
+			name:  "consistent index",
+			run:   declared(2, 1, 1),
+			items: good,
+		},
+		{
+			name:  "truncated index",
+			run:   declared(3, 2, 1),
+			items: good,
+			want:  []index.ProblemKind{index.ProblemTotal, index.ProblemOkCount},
+		},
+		{
+			name: "hand-edited items",
+			run:  declared(4, 4, 0),
+			items: []index.FileItem{
+				{Ok: true, Path: `\\nas\anime\a.mkv`, Length: 1, Hash: "AAA"},
+				{Ok: true, Path: `\\NAS\Anime\A.mkv`, Length: 1, Hash: "AAA"},
+				{Ok: true, Path: `\\nas\animex\c.mkv`, Length: 1, Hash: "CCC"},
+				{Ok: true, Path: `\\nas\anime\d.mkv`, Length: 1, Hash: " "},
+			},
+			want: []index.ProblemKind{index.ProblemDuplicate, index.ProblemOutsideRoot, index.ProblemEmptyHash},
+		},
+		{
+			name: "undeclared counts are not checked",
+			run:  index.RunInfo{Meta: map[string]any{"createdUtc": "yesterday"}},
+			items: []index.FileItem{
+				{Ok: true, Path: `/anywhere/a.mkv`, Length: 1, Hash: "AAA"},
+			},
+			want: []index.ProblemKind{index.ProblemTimestamp},
+		},
+		{
+			name: "unknown algorithms",
+			run:  index.RunInfo{Algorithm: "WHIRLPOOL"},
+			items: []index.FileItem{
+				{Ok: true, Path: `a.mkv`, Length: 1, Hash: "AAA", Hashes: map[string]string{"XXH64": "A", "FNV": "B"}},
+				{Ok: true, Path: `b.mkv`, Length: 1, Hash: "BBB", Hashes: map[string]string{"FNV": "C"}},
+			},
+			want: []index.ProblemKind{index.ProblemAlgorithm, index.ProblemAlgorithm},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			problems := index.Validate(tt.run, tt.items)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems mismatch:\n got:  %v\n want: %v", problems, tt.want)
+			}
+			for i := range tt.want {
+				if problems[i].Kind != tt.want[i] {
+					t.Fatalf("problem[%d] mismatch: got %v want %v", i, problems[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
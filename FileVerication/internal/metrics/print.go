@@ -6,18 +6,19 @@ import (
 )
 
 type Snapshot struct {
-	DurationMs     int64
-	Total          int64
-	Processed      int64
-	OK             int64
-	Skipped        int64
-	StatErrors     int64
-	SizeMismatches int64
-	HashErrors     int64
-	HashMismatches int64
-	BytesHashed    int64
-	BytesStatOK    int64
-	TotalBytes     int64
+	DurationMs     int64 `json:"durationMs"`
+	Total          int64 `json:"total"`
+	Processed      int64 `json:"processed"`
+	OK             int64 `json:"ok"`
+	Skipped        int64 `json:"skipped"`
+	StatErrors     int64 `json:"statErrors"`
+	Resolved       int64 `json:"resolvedWithFallback"`
+	SizeMismatches int64 `json:"sizeMismatches"`
+	HashErrors     int64 `json:"hashErrors"`
+	HashMismatches int64 `json:"hashMismatches"`
+	BytesHashed    int64 `json:"bytesHashed"`
+	BytesStatOK    int64 `json:"bytesStatOk"`
+	TotalBytes     int64 `json:"totalBytes"`
 }
 
 func (s *Stats) Snapshot() Snapshot {
@@ -30,6 +31,7 @@ func (s *Stats) Snapshot() Snapshot {
 		OK:             atomic.LoadInt64(&s.OK),
 		Skipped:        atomic.LoadInt64(&s.Skipped),
 		StatErrors:     atomic.LoadInt64(&s.StatErrors),
+		Resolved:       atomic.LoadInt64(&s.Resolved),
 		SizeMismatches: atomic.LoadInt64(&s.SizeMismatches),
 		HashErrors:     atomic.LoadInt64(&s.HashErrors),
 		HashMismatches: atomic.LoadInt64(&s.HashMismatches),
@@ -49,6 +51,7 @@ func Print(s *Stats) {
 	fmt.Println("ok:", snap.OK)
 	fmt.Println("skipped:", snap.Skipped)
 	fmt.Println("stat_errors:", snap.StatErrors)
+	fmt.Println("resolved_with_fallback:", snap.Resolved)
 	fmt.Println("size_mismatches:", snap.SizeMismatches)
 	fmt.Println("hash_errors:", snap.HashErrors)
 	fmt.Println("hash_mismatches:", snap.HashMismatches)
@@ -9,6 +9,7 @@ type Stats struct {
 	Total          int64
 	Skipped        int64
 	StatErrors     int64
+	Resolved       int64
 	SizeMismatches int64
 	HashErrors     int64
 	HashMismatches int64
@@ -30,6 +30,10 @@ func New(totalBytes int64, snap SnapshotFn) *Bar {
 		lastAt: time.Now(),
 	}
 
+	if totalBytes <= 0 {
+		totalBytes = -1
+	}
+
 	b.bar = progressbar.NewOptions64(
 		totalBytes,
 		progressbar.OptionSetWriter(os.Stdout),
@@ -77,6 +81,19 @@ func (b *Bar) AddBytes(n int64) {
 	b.ch <- n
 }
 
+// AddTotal grows the byte total, for callers that only learn the size of the
+// work while it is already in progress.
+func (b *Bar) AddTotal(n int64) {
+	if n <= 0 {
+		return
+	}
+	if b.bar.GetMax64() < 0 {
+		b.bar.ChangeMax64(n)
+		return
+	}
+	b.bar.AddMax64(n)
+}
+
 func (b *Bar) Close() {
 	close(b.stop)
 	close(b.ch)
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{"outcome", "path", "local_path", "length", "actual_length", "expected", "computed", "disagreed", "error", "duration_ms"}
+
+// WriteCSV writes one row per result.
+func WriteCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, rec := range r.Results {
+		actual := ""
+		if rec.ActualLength != nil {
+			actual = strconv.FormatInt(*rec.ActualLength, 10)
+		}
+		row := []string{
+			string(rec.Outcome), rec.Path, rec.LocalPath,
+			strconv.FormatInt(rec.Length, 10), actual,
+			rec.Expected, rec.Computed, strings.Join(rec.Disagreed, ";"), rec.Error,
+			strconv.FormatInt(rec.Duration.Milliseconds(), 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"FileVerication/internal/verify"
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+type htmlDir struct {
+	Dir     string
+	Records []verify.Record
+}
+
+var htmlPage = template.Must(template.New("report").Funcs(template.FuncMap{
+	"base": func(p string) string { return path.Base(strings.ReplaceAll(p, `\`, "/")) },
+	"join": strings.Join,
+	"pct": func(f float64) string {
+		return fmt.Sprintf("%.2f%%", 100*f)
+	},
+	"deref": func(n *int64) any {
+		if n == nil {
+			return ""
+		}
+		return *n
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Verification report: {{.R.Index}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+td.num { text-align: right; }
+code { font-size: 0.85em; word-break: break-all; }
+.partial { background: #fff3cd; border: 1px solid #e0c060; padding: 0.5em 1em; }
+.hash_mismatch, .size_mismatch { color: #b00020; }
+.stat_error, .hash_error { color: #a05a00; }
+.skipped { color: #666; }
+details { margin-bottom: 0.5em; }
+summary { cursor: pointer; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>Verification report</h1>
+{{if .R.Partial}}<p class="partial">Partial run: interrupted before every file was verified.</p>{{end}}
+<table>
+<tr><th>Index</th><td><code>{{.R.Index}}</code></td></tr>
+<tr><th>Root</th><td><code>{{.R.Run.Root}}</code></td></tr>
+<tr><th>Algorithm</th><td>{{.R.Run.Algorithm}}</td></tr>
+<tr><th>Started</th><td>{{.R.Started.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th>Finished</th><td>{{.R.Finished.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+</table>
+<h2>Totals</h2>
+<table>
+<tr><th>Files</th><td class="num">{{.R.Stats.Total}}</td></tr>
+<tr><th>Processed</th><td class="num">{{.R.Stats.Processed}}</td></tr>
+<tr><th>OK</th><td class="num">{{.R.Stats.OK}}</td></tr>
+<tr><th>Hash mismatches</th><td class="num">{{.R.Stats.HashMismatches}}</td></tr>
+<tr><th>Size mismatches</th><td class="num">{{.R.Stats.SizeMismatches}}</td></tr>
+<tr><th>Stat errors</th><td class="num">{{.R.Stats.StatErrors}}</td></tr>
+<tr><th>Hash errors</th><td class="num">{{.R.Stats.HashErrors}}</td></tr>
+<tr><th>Skipped</th><td class="num">{{.R.Stats.Skipped}}</td></tr>
+<tr><th>Bytes hashed</th><td class="num">{{.R.Stats.BytesHashed}}</td></tr>
+</table>
+{{with .R.Coverage}}
+<h2>Coverage</h2>
+<table>
+<tr><th>Files verified</th><td class="num">{{.VerifiedFiles}} of {{.PopulationFiles}} ({{pct .FileFraction}})</td></tr>
+<tr><th>Bytes verified</th><td class="num">{{.VerifiedBytes}} of {{.PopulationBytes}} ({{pct .ByteFraction}})</td></tr>
+<tr><th>Corrupt files found</th><td class="num">{{.Corrupt}}</td></tr>
+<tr><th>Corruption rate, 95% upper bound</th><td class="num">{{pct .UpperBound95}}</td></tr>
+{{if .RateThreshold}}<tr><th>Confidence rate &lt; {{pct .RateThreshold}}</th><td class="num">{{pct .Confidence}}</td></tr>{{end}}
+</table>
+{{end}}
+{{with .R.Ages}}
+<h2>Verification age by share</h2>
+<table>
+<tr><th>Share</th><th>Files</th><th>Never verified</th><th>Oldest verified OK</th><th>Oldest file</th></tr>
+{{range .}}<tr>
+<td><code>{{.Share}}</code></td><td class="num">{{.Files}}</td><td class="num">{{.NeverVerified}}</td>
+<td>{{if not .Oldest.IsZero}}{{.Oldest.Format "2006-01-02 15:04:05 MST"}}{{end}}</td><td><code>{{.OldestPath}}</code></td>
+</tr>
+{{end}}</table>
+{{end}}
+<h2>Files that did not verify ({{len .R.Results}})</h2>
+{{range .Dirs}}
+<details open>
+<summary><code>{{.Dir}}</code> ({{len .Records}})</summary>
+<table>
+<tr><th>Outcome</th><th>File</th><th>Length</th><th>Actual</th><th>Expected</th><th>Computed</th><th>Error</th></tr>
+{{range .Records}}<tr class="{{.Outcome}}">
+<td>{{.Outcome}}</td><td><code>{{base .Path}}</code></td>
+<td class="num">{{.Length}}</td><td class="num">{{deref .ActualLength}}</td>
+<td><code>{{.Expected}}</code></td><td><code>{{.Computed}}</code>{{with .Disagreed}}<br>disagreed: {{join . ", "}}{{end}}</td><td>{{.Error}}</td>
+</tr>
+{{end}}</table>
+</details>
+{{else}}
+<p>None.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained page with the run totals and the files
+// that did not verify, grouped by directory.
+func WriteHTML(w io.Writer, r *Report) error {
+	byDir := map[string][]verify.Record{}
+	for _, rec := range r.Results {
+		d := dirOf(rec.Path)
+		byDir[d] = append(byDir[d], rec)
+	}
+	dirs := make([]htmlDir, 0, len(byDir))
+	for d, recs := range byDir {
+		slices.SortFunc(recs, func(a, b verify.Record) int { return cmp.Compare(a.Path, b.Path) })
+		dirs = append(dirs, htmlDir{Dir: d, Records: recs})
+	}
+	slices.SortFunc(dirs, func(a, b htmlDir) int { return cmp.Compare(a.Dir, b.Dir) })
+
+	return htmlPage.Execute(w, struct {
+		R    *Report
+		Dirs []htmlDir
+	}{r, dirs})
+}
+
+// dirOf is the directory of an index path, which may use either separator.
+func dirOf(p string) string {
+	i := strings.LastIndexAny(p, `\/`)
+	if i < 0 {
+		return "."
+	}
+	return p[:i]
+}
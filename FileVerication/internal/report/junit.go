@@ -0,0 +1,135 @@
+package report
+
+import (
+	"FileVerication/internal/verify"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the run as a JUnit test suite. Each file that did not
+// verify is a test case: hash and size mismatches are failures, stat and
+// read errors are errors. Files that verified are summed up in one passing
+// case, so dashboards show the suite as run even when nothing failed.
+func WriteJUnit(w io.Writer, r *Report) error {
+	suite := junitSuite{
+		Name:     "filescanner " + r.Index,
+		Time:     seconds(r.Stats.DurationMs),
+		Failures: r.Failures(),
+	}
+	if !r.Started.IsZero() {
+		suite.Timestamp = r.Started.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	suite.Cases = append(suite.Cases, junitCase{
+		Name:      fmt.Sprintf("%d files verified", r.Stats.OK),
+		ClassName: "verify",
+		Time:      seconds(r.Stats.DurationMs),
+	})
+	if r.Partial {
+		suite.Errors++
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      "run completed",
+			ClassName: "verify",
+			Time:      "0",
+			Error:     &junitMessage{Message: "run was interrupted; results are partial", Type: "partial"},
+		})
+	}
+
+	for _, rec := range r.Results {
+		c := junitCase{
+			Name:      rec.Path,
+			ClassName: className(rec.Path),
+			Time:      fmt.Sprintf("%.3f", rec.Duration.Seconds()),
+		}
+		msg := &junitMessage{Message: string(rec.Outcome), Type: string(rec.Outcome), Text: detail(rec)}
+		switch rec.Outcome {
+		case verify.OutcomeMismatch, verify.OutcomeSizeMismatch:
+			c.Failure = msg
+		case verify.OutcomeSkipped:
+			suite.Skipped++
+			c.Skipped = msg
+		default:
+			suite.Errors++
+			c.Error = msg
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func detail(rec verify.Record) string {
+	var b strings.Builder
+	if rec.LocalPath != "" {
+		fmt.Fprintf(&b, "local path: %s\n", rec.LocalPath)
+	}
+	fmt.Fprintf(&b, "length: %d\n", rec.Length)
+	if rec.ActualLength != nil {
+		fmt.Fprintf(&b, "actual length: %d\n", *rec.ActualLength)
+	}
+	if rec.Expected != "" {
+		fmt.Fprintf(&b, "expected: %s\n", rec.Expected)
+	}
+	if rec.Computed != "" {
+		fmt.Fprintf(&b, "computed: %s\n", rec.Computed)
+	}
+	if len(rec.Disagreed) > 0 {
+		fmt.Fprintf(&b, "disagreed: %s\n", strings.Join(rec.Disagreed, ", "))
+	}
+	if rec.Error != "" {
+		fmt.Fprintf(&b, "error: %s\n", rec.Error)
+	}
+	return b.String()
+}
+
+// className is the directory of p, dotted the way JUnit viewers group by.
+func className(p string) string {
+	return strings.Trim(strings.NewReplacer(`\`, ".", "/", ".").Replace(dirOf(p)), ".")
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/verify"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report is everything a verification run produced, in the shape the
+// writers in this package share.
+type Report struct {
+	Index    string              `json:"index"`
+	Run      Run                 `json:"run"`
+	Started  time.Time           `json:"started"`
+	Finished time.Time           `json:"finished"`
+	Partial  bool                `json:"partial"`
+	Stats    metrics.Snapshot    `json:"stats"`
+	Results  []verify.Record     `json:"results"`
+	Resolved []verify.Resolution `json:"resolved,omitempty"`
+	Problems []string            `json:"indexProblems,omitempty"`
+	Coverage *verify.Coverage    `json:"coverage,omitempty"`
+	Ages     []verify.ShareAge   `json:"shareAges,omitempty"`
+}
+
+// Run is the part of index.RunInfo worth reporting.
+type Run struct {
+	Format     index.Format `json:"format"`
+	Algorithm  string       `json:"algorithm"`
+	Root       string       `json:"root,omitempty"`
+	CreatedUtc time.Time    `json:"createdUtc,omitzero"`
+	StartedUtc time.Time    `json:"startedUtc,omitzero"`
+	Total      int64        `json:"total"`
+}
+
+func NewRun(run index.RunInfo) Run {
+	return Run{
+		Format:     run.Format,
+		Algorithm:  run.Algorithm,
+		Root:       run.Root,
+		CreatedUtc: run.CreatedUtc,
+		StartedUtc: run.StartedUtc,
+		Total:      run.Total,
+	}
+}
+
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+	FormatJUnit Format = "junit"
+	FormatHTML  Format = "html"
+)
+
+// Target is one requested report: a format and the file to write it to.
+type Target struct {
+	Format Format
+	Path   string
+}
+
+// Targets implements flag.Value for -report. Each value is one or more
+// comma-separated format:path pairs, and the flag may be repeated.
+type Targets []Target
+
+func (t *Targets) String() string {
+	if t == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*t))
+	for _, tg := range *t {
+		pairs = append(pairs, string(tg.Format)+":"+tg.Path)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (t *Targets) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		format, path, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || path == "" {
+			return fmt.Errorf("report %q: want format:path", pair)
+		}
+		f := Format(strings.ToLower(format))
+		switch f {
+		case FormatJSON, FormatCSV, FormatJUnit, FormatHTML:
+		case "xml":
+			f = FormatJUnit
+		default:
+			return fmt.Errorf("report %q: unknown format %q (json, csv, junit, html)", pair, format)
+		}
+		*t = append(*t, Target{Format: f, Path: path})
+	}
+	return nil
+}
+
+// Write encodes r in the given format.
+func Write(w io.Writer, format Format, r *Report) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, r)
+	case FormatCSV:
+		return WriteCSV(w, r)
+	case FormatJUnit:
+		return WriteJUnit(w, r)
+	case FormatHTML:
+		return WriteHTML(w, r)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// WriteFile writes one report, leaving no partial file behind on error.
+func WriteFile(t Target, r *Report) error {
+	tmp := t.Path + ".tmp"
+	f, err := os.Create(tmp) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if err := Write(f, t.Format, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("%s report %s: %w", t.Format, t.Path, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, t.Path)
+}
+
+func WriteJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Failures counts the results that are failures rather than errors, in
+// the JUnit sense: the file was read and found to differ.
+func (r *Report) Failures() int {
+	n := 0
+	for _, rec := range r.Results {
+		if rec.Outcome == verify.OutcomeMismatch || rec.Outcome == verify.OutcomeSizeMismatch {
+			n++
+		}
+	}
+	return n
+}
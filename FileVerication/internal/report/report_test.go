@@ -0,0 +1,124 @@
+package report
+
+import (
+	"FileVerication/internal/metrics"
+	"FileVerication/internal/verify"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReport() *Report {
+	size := int64(9)
+	return &Report{
+		Index:    `\\nas\anime\AnimeHashIndex.clixml`,
+		Run:      Run{Format: "clixml", Algorithm: "SHA256", Root: `\\nas\anime`},
+		Started:  time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC),
+		Finished: time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC),
+		Stats:    metrics.Snapshot{Total: 5, Processed: 5, OK: 2, HashMismatches: 1, StatErrors: 1, Skipped: 1, DurationMs: 3600000},
+		Results: []verify.Record{
+			{Path: `\\nas\anime\Show A\ep01.mkv`, Outcome: verify.OutcomeMismatch, Length: 9, ActualLength: &size, Expected: "AA", Computed: "BB", Disagreed: []string{"SHA256"}, Duration: time.Second},
+			{Path: `\\nas\anime\Show A\ep02 <&>.mkv`, Outcome: verify.OutcomeStatError, Length: 3, Error: "file not found"},
+			{Path: `\\nas\anime\Show B\ep01.mkv`, Outcome: verify.OutcomeSkipped, Error: "prior error"},
+		},
+	}
+}
+
+func TestTargets_Set(t *testing.T) {
+	var ts Targets
+	if err := ts.Set("json:out.json,html:C:\\reports\\r.html"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := ts.Set("xml:junit.xml"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := Targets{{FormatJSON, "out.json"}, {FormatHTML, `C:\reports\r.html`}, {FormatJUnit, "junit.xml"}}
+	if len(ts) != len(want) {
+		t.Fatalf("targets mismatch: %+v", ts)
+	}
+	for i := range want {
+		if ts[i] != want[i] {
+			t.Fatalf("target[%d] mismatch: got %+v want %+v", i, ts[i], want[i])
+		}
+	}
+	for _, bad := range []string{"json", "pdf:out.pdf", "csv:"} {
+		if err := ts.Set(bad); err == nil {
+			t.Fatalf("Set(%q) should fail", bad)
+		}
+	}
+}
+
+func TestWrite_Formats(t *testing.T) {
+	r := sampleReport()
+	tests := []struct {
+		format Format
+		check  func(t *testing.T, out []byte)
+	}{
+		{FormatJSON, func(t *testing.T, out []byte) {
+			var got Report
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(got.Results) != 3 || got.Stats.HashMismatches != 1 || got.Run.Algorithm != "SHA256" {
+				t.Fatalf("json mismatch: %+v", got)
+			}
+			if !bytes.Contains(out, []byte(`"hashMismatches": 1`)) {
+				t.Fatalf("json stats not camelCase:\n%s", out)
+			}
+		}},
+		{FormatCSV, func(t *testing.T, out []byte) {
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			if len(lines) != 4 || !strings.HasPrefix(lines[1], `hash_mismatch,\\nas\anime\Show A\ep01.mkv,,9,9,AA,BB,SHA256,,1000`) {
+				t.Fatalf("csv mismatch:\n%s", out)
+			}
+		}},
+		{FormatJUnit, func(t *testing.T, out []byte) {
+			var got junitSuites
+			if err := xml.Unmarshal(out, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			s := got.Suites[0]
+			if s.Tests != 4 || s.Failures != 1 || s.Errors != 1 || s.Skipped != 1 {
+				t.Fatalf("suite counts mismatch: %+v", s)
+			}
+			if s.Cases[1].Failure == nil || s.Cases[1].ClassName != "nas.anime.Show A" {
+				t.Fatalf("failure case mismatch: %+v", s.Cases[1])
+			}
+		}},
+		{FormatHTML, func(t *testing.T, out []byte) {
+			page := string(out)
+			for _, want := range []string{`<code>\\nas\anime\Show A</code> (2)`, `<code>\\nas\anime\Show B</code> (1)`, "ep02 &lt;&amp;&gt;.mkv"} {
+				if !strings.Contains(page, want) {
+					t.Fatalf("html missing %q:\n%s", want, page)
+				}
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, r); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "r.json")
+	if err := WriteFile(Target{FormatJSON, p}, sampleReport()); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := os.Stat(p + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file left behind: %v", err)
+	}
+	if err := WriteFile(Target{FormatJSON, filepath.Join(t.TempDir(), "missing", "r.json")}, sampleReport()); err == nil {
+		t.Fatalf("WriteFile into a missing directory should fail")
+	}
+}
@@ -0,0 +1,79 @@
+package scan
+
+import (
+	"FileVerication/internal/index"
+	"fmt"
+	"iter"
+	"os"
+)
+
+// Input is a file to work on. Run is the run info of the index that
+// listed it, and zero for files found on disk.
+type Input struct {
+	Item index.FileItem
+	Run  index.RunInfo
+}
+
+// Inputs expands command-line arguments into files: directories are walked
+// with opts, files opts matches are taken as they are, and anything else is
+// read as an index, its paths mapped with m. Index entries recorded as
+// errors are left out. Errors are yielded as they occur and the remaining
+// arguments are still expanded.
+func Inputs(args []string, opts Options, m index.PathMap) iter.Seq2[Input, error] {
+	return func(yield func(Input, error) bool) {
+		for _, arg := range args {
+			info, err := os.Stat(arg)
+			switch {
+			case err != nil:
+				if !yield(Input{}, err) {
+					return
+				}
+			case info.IsDir():
+				files, errs := Walk(arg, opts)
+				for _, err := range errs {
+					if !yield(Input{}, err) {
+						return
+					}
+				}
+				for _, f := range files {
+					if !yield(Input{Item: index.FileItem{Path: f.Path, Length: f.Length}}, nil) {
+						return
+					}
+				}
+			case opts.Match(arg):
+				if !yield(Input{Item: index.FileItem{Path: arg, Length: info.Size()}}, nil) {
+					return
+				}
+			default:
+				if !indexInputs(arg, m, yield) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func indexInputs(path string, m index.PathMap, yield func(Input, error) bool) bool {
+	src, err := index.Open(path)
+	if err != nil {
+		return yield(Input{}, fmt.Errorf("%s: %w", path, err))
+	}
+	r := index.MapSource(src, m)
+	defer func() {
+		_ = r.Close()
+	}()
+
+	run := r.Run()
+	for fi, err := range r.All() {
+		if err != nil {
+			return yield(Input{}, fmt.Errorf("%s: %w", path, err))
+		}
+		if fi.Error != nil {
+			continue
+		}
+		if !yield(Input{Item: fi, Run: run}, nil) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package scan
+
+// Orphans walks root like Walk and returns the files for which known
+// reports false, i.e. files on disk that an index does not cover.
+func Orphans(root string, opts Options, known func(path string) bool) ([]File, []error) {
+	files, errs := Walk(root, opts)
+	orphans := files[:0]
+	for _, f := range files {
+		if !known(f.Path) {
+			orphans = append(orphans, f)
+		}
+	}
+	return orphans, errs
+}
@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Extension lists used by Build-VideoHashIndex-Parallel.ps1.
+var (
+	VideoExtensions   = []string{".mkv", ".mp4", ".avi"}
+	ExcludeExtensions = []string{".json", ".ndjson", ".ps1", ".clixml"}
+)
+
+type File struct {
+	Path    string
+	Length  int64
+	ModTime time.Time
+}
+
+type Options struct {
+	// Include lists the extensions to keep, lower case with the dot. Empty
+	// keeps every file that is not excluded.
+	Include []string
+	Exclude []string
+}
+
+func DefaultOptions() Options {
+	return Options{Include: VideoExtensions, Exclude: ExcludeExtensions}
+}
+
+// ParseExtensions reads a comma-separated extension list as -include and
+// -exclude flags take it, e.g. "mkv, .MP4", into the lower-case dotted
+// form Options uses.
+func ParseExtensions(s string) []string {
+	var exts []string
+	for _, e := range strings.Split(s, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		exts = append(exts, e)
+	}
+	return exts
+}
+
+// Match reports whether name passes the extension filters. Names without an
+// extension never match.
+func (o Options) Match(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" || slices.Contains(o.Exclude, ext) {
+		return false
+	}
+	return len(o.Include) == 0 || slices.Contains(o.Include, ext)
+}
+
+// Walk lists the regular files under root that pass the filters, sorted by
+// path. Like Get-ChildItem -ErrorAction SilentlyContinue, unreadable
+// directories are skipped; their errors are returned alongside the files.
+func Walk(root string, opts Options) ([]File, []error) {
+	var files []File
+	var errs []error
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			if d != nil && d.IsDir() && p != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !opts.Match(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		files = append(files, File{Path: p, Length: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, errs
+}
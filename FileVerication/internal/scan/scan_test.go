@@ -0,0 +1,128 @@
+package scan
+
+import (
+	"FileVerication/internal/index"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWalk_Filters(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{
+		"b.mkv":                 2,
+		"a.MP4":                 1,
+		"sub/c.avi":             3,
+		"sub/notes.txt":         4,
+		"AnimeHashIndex.clixml": 5,
+		"journal.ndjson":        6,
+		"noext":                 7,
+	}
+	for name, size := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	got, errs := Walk(root, DefaultOptions())
+	if len(errs) != 0 {
+		t.Fatalf("Walk errors: %v", errs)
+	}
+	want := []File{
+		{Path: filepath.Join(root, "a.MP4"), Length: 1},
+		{Path: filepath.Join(root, "b.mkv"), Length: 2},
+		{Path: filepath.Join(root, "sub", "c.avi"), Length: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("files mismatch: got %+v want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Path != want[i].Path || got[i].Length != want[i].Length || got[i].ModTime.IsZero() {
+			t.Fatalf("file[%d] mismatch: got %+v want %+v", i, got[i], want[i])
+		}
+	}
+
+	all, _ := Walk(root, Options{Exclude: ExcludeExtensions})
+	if len(all) != 4 {
+		t.Fatalf("empty Include should keep every non-excluded file with an extension, got %+v", all)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"indexed.mkv", "Indexed Too.mp4", "new.avi", "new.ps1"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	known := map[string]bool{
+		filepath.Join(root, "indexed.mkv"):     true,
+		filepath.Join(root, "Indexed Too.mp4"): true,
+	}
+
+	got, errs := Orphans(root, DefaultOptions(), func(p string) bool { return known[p] })
+	if len(errs) != 0 {
+		t.Fatalf("Orphans errors: %v", errs)
+	}
+	if len(got) != 1 || got[0].Path != filepath.Join(root, "new.avi") || got[0].Length != 7 {
+		t.Fatalf("orphans mismatch: %+v", got)
+	}
+}
+
+func TestParseExtensions(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"mkv,mp4", []string{".mkv", ".mp4"}},
+		{" .MKV , avi ,,", []string{".mkv", ".avi"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseExtensions(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseExtensions(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInputs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dir")
+	for _, p := range []string{filepath.Join(dir, "a.mkv"), filepath.Join(dir, "notes.txt"), filepath.Join(root, "b.mkv")} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	journal := filepath.Join(root, "index.ndjson")
+	lines := `{"ok":true,"path":"\\\\nas\\anime\\c.mkv","length":1,"hash":"AA"}` + "\n" +
+		`{"ok":false,"path":"\\\\nas\\anime\\d.mkv","error":"access denied"}` + "\n"
+	if err := os.WriteFile(journal, []byte(lines), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var m index.PathMap
+	if err := m.Set(`\\nas\anime=` + root); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	var errs int
+	for in, err := range Inputs([]string{dir, filepath.Join(root, "b.mkv"), journal, filepath.Join(root, "missing")}, DefaultOptions(), m) {
+		if err != nil {
+			errs++
+			continue
+		}
+		got = append(got, in.Item.FSPath())
+	}
+	want := []string{filepath.Join(dir, "a.mkv"), filepath.Join(root, "b.mkv"), filepath.Join(root, "c.mkv")}
+	if !slices.Equal(got, want) || errs != 1 {
+		t.Fatalf("inputs:\n got %q (%d errors)\nwant %q (1 error)", got, errs, want)
+	}
+}
@@ -0,0 +1,187 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Outcome is what verifying one file concluded.
+type Outcome string
+
+const (
+	OutcomeOK           Outcome = "ok"
+	OutcomeMismatch     Outcome = "hash_mismatch"
+	OutcomeSizeMismatch Outcome = "size_mismatch"
+	OutcomeStatError    Outcome = "stat_error"
+	OutcomeHashError    Outcome = "hash_error"
+	OutcomeSkipped      Outcome = "skipped"
+)
+
+// Record is the outcome of verifying one index item. Length and Expected
+// come from the index; ActualLength is nil when the file was never stat'ed.
+type Record struct {
+	Path         string        `json:"path"`
+	LocalPath    string        `json:"localPath,omitempty"`
+	Outcome      Outcome       `json:"outcome"`
+	Length       int64         `json:"length"`
+	ActualLength *int64        `json:"actualLength,omitempty"`
+	Expected     string        `json:"expected,omitempty"`
+	Computed     string        `json:"computed,omitempty"`
+	Disagreed    []string      `json:"disagreed,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Duration     time.Duration `json:"durationNs"`
+}
+
+// Count adds the record to the counters VerifyStream would have updated for
+// it, so a resumed run starts from the totals of the interrupted one.
+func (r Record) Count(stats *metrics.Stats) {
+	atomic.AddInt64(&stats.Processed, 1)
+	switch r.Outcome {
+	case OutcomeOK:
+		atomic.AddInt64(&stats.OK, 1)
+	case OutcomeMismatch:
+		atomic.AddInt64(&stats.HashMismatches, 1)
+	case OutcomeSizeMismatch:
+		atomic.AddInt64(&stats.SizeMismatches, 1)
+	case OutcomeStatError:
+		atomic.AddInt64(&stats.StatErrors, 1)
+	case OutcomeHashError:
+		atomic.AddInt64(&stats.HashErrors, 1)
+	case OutcomeSkipped:
+		atomic.AddInt64(&stats.Skipped, 1)
+	}
+}
+
+// Mismatch returns the record as a Mismatch, for records with OutcomeMismatch.
+func (r Record) Mismatch() Mismatch {
+	return Mismatch{Path: r.Path, LocalPath: r.LocalPath, Expected: r.Expected, Computed: r.Computed, Disagreed: r.Disagreed}
+}
+
+// Checkpoint is an append-only NDJSON log of completed files, one Record
+// per line, synced as it is written. It is safe for concurrent use, so
+// Checkpoint.Add can be passed as Options.OnRecord.
+type Checkpoint struct {
+	mu  sync.Mutex
+	f   *os.File
+	err error
+}
+
+// ErrCheckpointExists is returned by OpenCheckpoint when a new run would
+// discard the progress an earlier one recorded.
+var ErrCheckpointExists = errors.New("checkpoint already records progress")
+
+// CheckpointMode says what OpenCheckpoint does with an existing checkpoint.
+type CheckpointMode int
+
+const (
+	// CheckpointNew starts a checkpoint, refusing to replace a non-empty one.
+	CheckpointNew CheckpointMode = iota
+	// CheckpointResume appends to the checkpoint of an interrupted run.
+	CheckpointResume
+	// CheckpointOverwrite starts afresh, discarding any earlier records.
+	CheckpointOverwrite
+)
+
+// OpenCheckpoint opens the checkpoint at path according to mode.
+func OpenCheckpoint(path string, mode CheckpointMode) (*Checkpoint, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if mode == CheckpointOverwrite {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644) // #nosec G302 G304
+	if err != nil {
+		return nil, err
+	}
+	if mode == CheckpointNew {
+		info, err := f.Stat()
+		if err == nil && info.Size() > 0 {
+			err = fmt.Errorf("%s: %w", path, ErrCheckpointExists)
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return &Checkpoint{f: f}, nil
+}
+
+// Add appends rec. Write errors are kept and returned by Close; a
+// checkpoint that cannot be written must not stop the verification.
+func (c *Checkpoint) Add(rec Record) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	if _, err := c.f.Write(append(line, '\n')); err != nil {
+		c.err = err
+		return
+	}
+	c.err = c.f.Sync()
+}
+
+func (c *Checkpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.f.Close(); err != nil && c.err == nil {
+		c.err = err
+	}
+	return c.err
+}
+
+// Completed holds the records of an earlier run, keyed like the history
+// store on path and expected hash, so a record does not carry over to a
+// file whose hash the index has since changed.
+type Completed map[string]Record
+
+// Lookup returns the record for fi, if there is one for its path and hash.
+func (c Completed) Lookup(fi index.FileItem) (Record, bool) {
+	rec, ok := c[fileKey(fi.Path, fi.Hash)]
+	return rec, ok
+}
+
+// LoadCheckpoint reads the records of an earlier run. A missing file yields
+// no records; torn or malformed lines are ignored, and a later record for a
+// file replaces an earlier one.
+func LoadCheckpoint(path string) (Completed, error) {
+	done := Completed{}
+	f, err := os.Open(path) // #nosec G304
+	if errors.Is(err, fs.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.Path == "" || rec.Outcome == "" {
+			continue
+		}
+		done[fileKey(rec.Path, rec.Expected)] = rec
+	}
+	return done, sc.Err()
+}
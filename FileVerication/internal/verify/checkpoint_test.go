@@ -0,0 +1,96 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint_RoundTripAndResume(t *testing.T) {
+	dir := t.TempDir()
+	good := writeFile(t, dir, "good.bin", []byte("good"))
+	goodHash, err := hashHexUpper("SHA256", []byte("good"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []index.FileItem{
+		{Ok: true, Path: good, Length: 4, Hash: goodHash},
+		{Ok: true, Path: good, Length: 4, Hash: "WRONG"},
+		{Ok: true, Path: filepath.Join(dir, "missing.bin"), Length: 1, Hash: "X"},
+		{Ok: false, Path: filepath.Join(dir, "skipped.bin"), Error: ptr("prior error")},
+	}
+
+	cpPath := filepath.Join(dir, "run.checkpoint.ndjson")
+	cp, err := OpenCheckpoint(cpPath, CheckpointNew)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint: %v", err)
+	}
+	Verify(context.Background(), "SHA256", items, Options{Workers: 2, OnRecord: cp.Add}, &metrics.Stats{}, nil)
+	if err := cp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A torn final line from a crash must not break loading.
+	f, err := os.OpenFile(cpPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"path":"` + filepath.ToSlash(dir) + `/torn`)
+	_ = f.Close()
+
+	done, err := LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	// good.bin appears under two hashes, as after an index rebuild; each
+	// keeps its own record.
+	if len(done) != 4 {
+		t.Fatalf("records mismatch: got %d: %+v", len(done), done)
+	}
+	if rec, _ := done.Lookup(items[0]); rec.Outcome != OutcomeOK {
+		t.Fatalf("good.bin record mismatch: %+v", rec)
+	}
+	if rec, _ := done.Lookup(items[1]); rec.Outcome != OutcomeMismatch {
+		t.Fatalf("rehashed good.bin record mismatch: %+v", rec)
+	}
+	if rec, _ := done.Lookup(items[2]); rec.Outcome != OutcomeStatError || rec.Error == "" {
+		t.Fatalf("missing.bin record mismatch: %+v", rec)
+	}
+	if rec, _ := done.Lookup(items[3]); rec.Outcome != OutcomeSkipped {
+		t.Fatalf("skipped.bin record mismatch: %+v", rec)
+	}
+	if _, ok := done.Lookup(index.FileItem{Path: good, Hash: "CHANGED"}); ok {
+		t.Fatal("record matched a file whose hash changed")
+	}
+
+	stats := &metrics.Stats{}
+	for _, rec := range done {
+		rec.Count(stats)
+	}
+	if stats.Processed != 4 || stats.StatErrors != 1 || stats.Skipped != 1 || stats.OK != 1 || stats.HashMismatches != 1 {
+		t.Fatalf("seeded stats mismatch: %+v", stats)
+	}
+
+	// Starting a new run must not wipe the interrupted one's progress.
+	if _, err := OpenCheckpoint(cpPath, CheckpointNew); !errors.Is(err, ErrCheckpointExists) {
+		t.Fatalf("OpenCheckpoint over a non-empty checkpoint: got %v", err)
+	}
+	cp, err = OpenCheckpoint(cpPath, CheckpointOverwrite)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint overwrite: %v", err)
+	}
+	if err := cp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if done, err := LoadCheckpoint(cpPath); err != nil || len(done) != 0 {
+		t.Fatalf("overwritten checkpoint: %d records, %v", len(done), err)
+	}
+
+	if _, err := LoadCheckpoint(filepath.Join(dir, "none.ndjson")); err != nil {
+		t.Fatalf("LoadCheckpoint of missing file: %v", err)
+	}
+}
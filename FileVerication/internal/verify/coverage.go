@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"FileVerication/internal/metrics"
+	"math"
+)
+
+// Coverage describes how much of an index a sampled or budgeted run
+// checked, and what that says about the corruption rate of the rest.
+type Coverage struct {
+	PopulationFiles int64   `json:"populationFiles"`
+	PopulationBytes int64   `json:"populationBytes"`
+	VerifiedFiles   int64   `json:"verifiedFiles"`
+	VerifiedBytes   int64   `json:"verifiedBytes"`
+	Corrupt         int64   `json:"corrupt"`
+	FileFraction    float64 `json:"fileFraction"`
+	ByteFraction    float64 `json:"byteFraction"`
+	// UpperBound95 is the one-sided 95% upper confidence bound on the
+	// per-file corruption rate (Clopper-Pearson).
+	UpperBound95 float64 `json:"upperBound95"`
+	// Confidence is how sure the run makes us that the corruption rate is
+	// below RateThreshold.
+	RateThreshold float64 `json:"rateThreshold,omitempty"`
+	Confidence    float64 `json:"confidence,omitempty"`
+}
+
+// NewCoverage computes coverage figures for a run over a population of
+// files and bytes. Files count as verified when they were read and compared;
+// hash and size mismatches count as corrupt. Files are treated as drawn
+// independently, which is conservative for sampling without replacement.
+func NewCoverage(populationFiles, populationBytes int64, s metrics.Snapshot, rateThreshold float64) Coverage {
+	c := Coverage{
+		PopulationFiles: populationFiles,
+		PopulationBytes: populationBytes,
+		VerifiedFiles:   s.OK + s.HashMismatches + s.SizeMismatches,
+		VerifiedBytes:   s.BytesHashed,
+		Corrupt:         s.HashMismatches + s.SizeMismatches,
+		RateThreshold:   rateThreshold,
+	}
+	if populationFiles > 0 {
+		c.FileFraction = float64(c.VerifiedFiles) / float64(populationFiles)
+	}
+	if populationBytes > 0 {
+		c.ByteFraction = math.Min(1, float64(c.VerifiedBytes)/float64(populationBytes))
+	}
+	c.UpperBound95 = rateUpperBound(c.VerifiedFiles, c.Corrupt, 0.95)
+	if rateThreshold > 0 {
+		c.Confidence = 1 - binomCDF(c.Corrupt, c.VerifiedFiles, rateThreshold)
+	}
+	return c
+}
+
+// rateUpperBound is the smallest p with P(X <= k | n, p) <= 1-conf.
+func rateUpperBound(n, k int64, conf float64) float64 {
+	if n == 0 || k >= n {
+		return 1
+	}
+	if k == 0 {
+		return 1 - math.Pow(1-conf, 1/float64(n))
+	}
+	lo, hi := float64(k)/float64(n), 1.0
+	for range 100 {
+		mid := (lo + hi) / 2
+		if binomCDF(k, n, mid) > 1-conf {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// binomCDF is P(X <= k) for X ~ Binomial(n, p).
+func binomCDF(k, n int64, p float64) float64 {
+	switch {
+	case k >= n || p <= 0:
+		return 1
+	case p >= 1:
+		return 0
+	}
+	lp, lq := math.Log(p), math.Log1p(-p)
+	lgn, _ := math.Lgamma(float64(n) + 1)
+	var sum float64
+	for i := int64(0); i <= k; i++ {
+		lgi, _ := math.Lgamma(float64(i) + 1)
+		lgni, _ := math.Lgamma(float64(n-i) + 1)
+		sum += math.Exp(lgn - lgi - lgni + float64(i)*lp + float64(n-i)*lq)
+	}
+	return math.Min(1, sum)
+}
@@ -0,0 +1,21 @@
+package verify
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// adviseSequential asks for aggressive read-ahead on the range about to be
+// hashed. Advice is best effort; errors are ignored.
+func adviseSequential(f *os.File, off, length int64) {
+	if length < 0 {
+		length = 0 // to end of file
+	}
+	_ = unix.Fadvise(int(f.Fd()), off, length, unix.FADV_SEQUENTIAL)
+}
+
+// adviseDontNeed drops a range that has been hashed from the page cache.
+func adviseDontNeed(f *os.File, off, length int64) {
+	_ = unix.Fadvise(int(f.Fd()), off, length, unix.FADV_DONTNEED)
+}
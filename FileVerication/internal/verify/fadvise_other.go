@@ -0,0 +1,9 @@
+//go:build !linux
+
+package verify
+
+import "os"
+
+func adviseSequential(*os.File, int64, int64) {}
+
+func adviseDontNeed(*os.File, int64, int64) {}
@@ -1,10 +1,9 @@
 package verify
 
 import (
-	"crypto/md5"  // #nosec G501 -- used for file integrity verification only
-	"crypto/sha1" // #nosec G505 -- used for file integrity verification only
-	"crypto/sha256"
-	"crypto/sha512"
+	"FileVerication/internal/hashalg"
+	"FileVerication/internal/index"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -13,25 +12,14 @@ import (
 	"strings"
 )
 
-func newHasher(algorithm string) (hash.Hash, error) {
-	switch strings.ToUpper(strings.TrimSpace(algorithm)) {
-	case "SHA256":
-		return sha256.New(), nil
-	case "SHA1":
-		return sha1.New(), nil // #nosec G401 -- used for file integrity verification only
-	case "SHA512":
-		return sha512.New(), nil
-	case "SHA384":
-		return sha512.New384(), nil
-	case "MD5":
-		return md5.New(), nil // #nosec G401 -- used for file integrity verification only
-	default:
-		return nil, fmt.Errorf("unsupported algorithm: %q", algorithm)
-	}
+func FileHashHex(path string, algorithm string, onProgress func(n int64)) (string, error) {
+	return FileHashHexContext(context.Background(), path, algorithm, onProgress)
 }
 
-func FileHashHex(path string, algorithm string, onProgress func(n int64)) (string, error) {
-	h, err := newHasher(algorithm)
+// FileHashHexContext is FileHashHex that gives up between reads once ctx is
+// done, returning ctx.Err().
+func FileHashHexContext(ctx context.Context, path string, algorithm string, onProgress func(n int64)) (string, error) {
+	h, err := hashalg.New(algorithm)
 	if err != nil {
 		return "", err
 	}
@@ -47,36 +35,60 @@ func FileHashHex(path string, algorithm string, onProgress func(n int64)) (strin
 		}
 	}(f)
 
-	buf := make([]byte, 1<<20) // 1 MiB
-	var pending int64
-	flush := func() {
-		if pending > 0 && onProgress != nil {
-			onProgress(pending)
-			pending = 0
-		}
+	if _, err := hashFile(ctx, f, 0, -1, h, Options{}, onProgress); err != nil {
+		return "", err
 	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
+}
 
-	for {
-		n, rerr := f.Read(buf)
-		if n > 0 {
-			if _, werr := h.Write(buf[:n]); werr != nil {
-				return "", werr
-			}
-			pending += int64(n)
-			if pending >= int64(1<<20) {
-				flush()
-			}
-		}
-		if rerr == io.EOF {
-			break
+// FileHashesHex reads path once and hashes it with every algorithm in
+// algorithms, returning the upper-case hex digests keyed by
+// index.AlgorithmKey.
+func FileHashesHex(path string, algorithms []string, onProgress func(n int64)) (map[string]string, error) {
+	return FileHashesHexContext(context.Background(), path, algorithms, onProgress)
+}
+
+// FileHashesHexContext is FileHashesHex that gives up once ctx is done.
+func FileHashesHexContext(ctx context.Context, path string, algorithms []string, onProgress func(n int64)) (map[string]string, error) {
+	return fileHashes(ctx, path, algorithms, Options{}, onProgress)
+}
+
+// fileHashes is FileHashesHexContext reading as opts says.
+func fileHashes(ctx context.Context, path string, algorithms []string, opts Options, onProgress func(n int64)) (map[string]string, error) {
+	hashers := map[string]hash.Hash{}
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, alg := range algorithms {
+		key := index.AlgorithmKey(alg)
+		if _, ok := hashers[key]; ok {
+			continue
 		}
-		if rerr != nil {
-			return "", rerr
+		h, err := hashalg.New(key)
+		if err != nil {
+			return nil, err
 		}
+		hashers[key] = h
+		writers = append(writers, h)
+	}
+	if len(writers) == 0 {
+		return nil, fmt.Errorf("no hash algorithms given")
 	}
-	flush()
 
-	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if _, err := hashFile(ctx, f, 0, -1, io.MultiWriter(writers...), opts, onProgress); err != nil {
+		return nil, err
+	}
+	digests := make(map[string]string, len(hashers))
+	for key, h := range hashers {
+		digests[key] = strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+	}
+	return digests, nil
 }
 
 func FileHashHexRange(path string, algorithm string, start, length int64, onProgress func(n int64)) (string, error) {
@@ -84,7 +96,7 @@ func FileHashHexRange(path string, algorithm string, start, length int64, onProg
 		return "", fmt.Errorf("invalid range: start=%d length=%d", start, length)
 	}
 
-	h, err := newHasher(algorithm)
+	h, err := hashalg.New(algorithm)
 	if err != nil {
 		return "", err
 	}
@@ -97,50 +109,9 @@ func FileHashHexRange(path string, algorithm string, start, length int64, onProg
 		_ = f.Close()
 	}()
 
-	const bufSize = 1 << 20 // 1 MiB
-	buf := make([]byte, bufSize)
-
-	var pending int64
-	flush := func() {
-		if pending > 0 && onProgress != nil {
-			onProgress(pending)
-			pending = 0
-		}
-	}
-
-	var read int64
-	for read < length {
-		toRead := int64(len(buf))
-		remain := length - read
-		if remain < toRead {
-			toRead = remain
-		}
-
-		n, rerr := f.ReadAt(buf[:toRead], start+read)
-		if n > 0 {
-			if _, werr := h.Write(buf[:n]); werr != nil {
-				return "", werr
-			}
-			pending += int64(n)
-			if pending >= bufSize {
-				flush()
-			}
-			read += int64(n)
-		}
-
-		if rerr != nil {
-			// If we got EOF early, the file is shorter than start+length.
-			if rerr == io.EOF && read == length {
-				break
-			}
-			if rerr == io.EOF {
-				return "", fmt.Errorf("unexpected EOF at offset %d (wanted %d bytes total)", start+read, length)
-			}
-			return "", rerr
-		}
+	if _, err := hashFile(context.Background(), f, start, length, h, Options{}, onProgress); err != nil {
+		return "", err
 	}
-
-	flush()
 	return strings.ToUpper(hex.EncodeToString(h.Sum(nil))), nil
 }
 
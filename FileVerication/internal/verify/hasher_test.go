@@ -62,7 +62,7 @@ func TestFileHashHex_TableDriven(t *testing.T) {
 		{"sha512", "SHA512", contentSmall, false, false},
 		{"sha384", "SHA384", contentSmall, false, false},
 		{"md5", "MD5", contentSmall, false, false},
-		{"unsupported algorithm", "BLAKE3", contentSmall, false, true},
+		{"unsupported algorithm", "WHIRLPOOL", contentSmall, false, true},
 		{"file missing", "SHA256", contentSmall, true, true},
 	}
 
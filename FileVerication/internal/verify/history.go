@@ -0,0 +1,261 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is what the history store remembers about one file.
+// Checked is the last time it was looked at, whatever the outcome; LastOK
+// is the last time it verified OK and is zero if it never has.
+type HistoryEntry struct {
+	Path    string    `json:"path"`
+	Hash    string    `json:"hash"`
+	Checked time.Time `json:"checked"`
+	Outcome Outcome   `json:"outcome"`
+	LastOK  time.Time `json:"lastOk,omitzero"`
+}
+
+// History is a persistent per-file record of when each file was last
+// verified, kept next to the index so that rolling runs can check the
+// least recently verified files first. Entries are matched on path and
+// hash, so they survive the index being rebuilt but not the file changing.
+//
+// The store is an NDJSON file, compacted when opened and appended to as
+// files complete; the last line for a file wins. History is safe for
+// concurrent use, so History.Add can be called from Options.OnRecord.
+type History struct {
+	mu      sync.Mutex
+	entries map[string]HistoryEntry
+	f       *os.File
+	err     error
+}
+
+// OpenHistory loads the store at path, creating it if it does not exist.
+// Torn or malformed lines are ignored.
+func OpenHistory(path string) (*History, error) {
+	h := &History{entries: map[string]HistoryEntry{}}
+	if err := h.load(path); err != nil {
+		return nil, err
+	}
+	if err := h.compact(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644) // #nosec G302 G304
+	if err != nil {
+		return nil, err
+	}
+	h.f = f
+	return h, nil
+}
+
+func (h *History) load(path string) error {
+	f, err := os.Open(path) // #nosec G304
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil || e.Path == "" || e.Checked.IsZero() {
+			continue
+		}
+		h.entries[fileKey(e.Path, e.Hash)] = e
+	}
+	return sc.Err()
+}
+
+// compact rewrites the store with one line per file.
+func (h *History) compact(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp) // #nosec G304
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, k := range slices.Sorted(maps.Keys(h.entries)) {
+		if err := enc.Encode(h.entries[k]); err != nil {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Add records the outcome of verifying a file now. Skipped files, which
+// have no hash to check, are not recorded. Write errors are kept and
+// returned by Close.
+func (h *History) Add(rec Record) {
+	if rec.Outcome == OutcomeSkipped {
+		return
+	}
+	now := time.Now().UTC()
+	key := fileKey(rec.Path, rec.Expected)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e := h.entries[key]
+	e.Path, e.Hash, e.Checked, e.Outcome = rec.Path, rec.Expected, now, rec.Outcome
+	if rec.Outcome == OutcomeOK {
+		e.LastOK = now
+	}
+	h.entries[key] = e
+
+	if h.err != nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, h.err = h.f.Write(append(line, '\n'))
+}
+
+// Last returns the entry for fi, if the store has one for its path and hash.
+func (h *History) Last(fi index.FileItem) (HistoryEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.entries[fileKey(fi.Path, fi.Hash)]
+	return e, ok
+}
+
+func (h *History) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.f.Sync(); err != nil && h.err == nil {
+		h.err = err
+	}
+	if err := h.f.Close(); err != nil && h.err == nil {
+		h.err = err
+	}
+	return h.err
+}
+
+// SortOldestFirst orders items for a rolling run: files that never
+// verified OK first, then by the time they last did, oldest first. A file
+// that failed to stat or hash keeps its place, as it was not verified.
+func (h *History) SortOldestFirst(items []index.FileItem) {
+	lastOK := make(map[string]time.Time, len(items))
+	for _, fi := range items {
+		if e, ok := h.Last(fi); ok {
+			lastOK[fi.Path] = e.LastOK
+		}
+	}
+	slices.SortStableFunc(items, func(a, b index.FileItem) int {
+		if c := lastOK[a.Path].Compare(lastOK[b.Path]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+}
+
+// ShareAge summarises, for one share, how long its files have gone without
+// verifying OK. Oldest is the earliest LastOK among files that have one.
+type ShareAge struct {
+	Share         string    `json:"share"`
+	Files         int64     `json:"files"`
+	NeverVerified int64     `json:"neverVerified"`
+	Oldest        time.Time `json:"oldest,omitzero"`
+	OldestPath    string    `json:"oldestPath,omitempty"`
+}
+
+// Ages groups items by share and reports the oldest verification age of each.
+func (h *History) Ages(items []index.FileItem) []ShareAge {
+	byShare := map[string]*ShareAge{}
+	for _, fi := range items {
+		if fi.Error != nil {
+			continue
+		}
+		s := shareOf(fi.Path)
+		a := byShare[s]
+		if a == nil {
+			a = &ShareAge{Share: s}
+			byShare[s] = a
+		}
+		a.Files++
+		e, ok := h.Last(fi)
+		if !ok || e.LastOK.IsZero() {
+			a.NeverVerified++
+			continue
+		}
+		if a.Oldest.IsZero() || e.LastOK.Before(a.Oldest) {
+			a.Oldest, a.OldestPath = e.LastOK, fi.Path
+		}
+	}
+
+	ages := make([]ShareAge, 0, len(byShare))
+	for _, s := range slices.Sorted(maps.Keys(byShare)) {
+		ages = append(ages, *byShare[s])
+	}
+	return ages
+}
+
+func fileKey(path, hash string) string {
+	return index.PathKey(path) + "\x00" + strings.ToUpper(strings.TrimSpace(hash))
+}
+
+// shareOf is the share an index path lives on: \\host\share for UNC paths,
+// the drive for drive-letter paths, and the first two directories otherwise
+// (/mnt/anime).
+func shareOf(p string) string {
+	parts := strings.FieldsFunc(p, func(r rune) bool { return r == '\\' || r == '/' })
+	switch {
+	case strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, "//"):
+		if len(parts) >= 2 {
+			return `\\` + parts[0] + `\` + parts[1]
+		}
+	case len(p) >= 2 && p[1] == ':':
+		return strings.ToUpper(p[:2]) + `\`
+	case strings.HasPrefix(p, "/"):
+		if len(parts) > 2 {
+			return "/" + parts[0] + "/" + parts[1]
+		}
+		if len(parts) > 1 {
+			return "/" + parts[0]
+		}
+		return "/"
+	}
+	if len(parts) > 1 {
+		return parts[0]
+	}
+	return "."
+}
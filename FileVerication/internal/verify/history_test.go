@@ -0,0 +1,110 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistory_PersistsAndMatchesPathAndHash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idx.history.ndjson")
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Add(Record{Path: `\\nas\anime\a.mkv`, Outcome: OutcomeOK, Expected: "aa"})
+	h.Add(Record{Path: `\\nas\anime\b.mkv`, Outcome: OutcomeMismatch, Expected: "BB"})
+	h.Add(Record{Path: `\\nas\anime\c.mkv`, Outcome: OutcomeSkipped})
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = h.Close()
+	}()
+
+	tests := []struct {
+		name   string
+		fi     index.FileItem
+		found  bool
+		lastOK bool
+	}{
+		{"rebuilt index, same hash", index.FileItem{Path: `\\NAS\anime\a.mkv`, Hash: "AA"}, true, true},
+		{"file changed", index.FileItem{Path: `\\nas\anime\a.mkv`, Hash: "CC"}, false, false},
+		{"mismatch recorded", index.FileItem{Path: `\\nas\anime\b.mkv`, Hash: "bb"}, true, false},
+		{"skipped not recorded", index.FileItem{Path: `\\nas\anime\c.mkv`}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := h.Last(tt.fi)
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if !e.LastOK.IsZero() != tt.lastOK {
+				t.Fatalf("LastOK = %v, want set %v", e.LastOK, tt.lastOK)
+			}
+		})
+	}
+}
+
+func TestHistory_SortOldestFirstAndAges(t *testing.T) {
+	h := &History{entries: map[string]HistoryEntry{}}
+	now := time.Now().UTC()
+	for i, p := range []string{`D:\v\old.mkv`, `D:\v\new.mkv`} {
+		at := now.Add(-time.Duration(10-i*9) * time.Hour)
+		h.entries[fileKey(p, "H")] = HistoryEntry{Path: p, Hash: "H", Checked: at, Outcome: OutcomeOK, LastOK: at}
+	}
+	// Checked just now, but it failed to stat: still never verified.
+	h.entries[fileKey(`D:\v\failed.mkv`, "H")] = HistoryEntry{Path: `D:\v\failed.mkv`, Hash: "H", Checked: now, Outcome: OutcomeStatError}
+	items := []index.FileItem{
+		{Path: `D:\v\failed.mkv`, Hash: "H"},
+		{Path: `D:\v\new.mkv`, Hash: "H"},
+		{Path: `D:\v\old.mkv`, Hash: "H"},
+		{Path: `D:\v\unseen.mkv`, Hash: "H"},
+		{Path: `\\nas\anime\x.mkv`, Hash: "H"},
+	}
+	h.SortOldestFirst(items)
+	var got []string
+	for _, fi := range items {
+		got = append(got, fi.Path)
+	}
+	want := []string{`D:\v\failed.mkv`, `D:\v\unseen.mkv`, `\\nas\anime\x.mkv`, `D:\v\old.mkv`, `D:\v\new.mkv`}
+	if !slices.Equal(got, want) {
+		t.Fatalf("order:\n got %q\nwant %q", got, want)
+	}
+
+	ages := h.Ages(items)
+	if len(ages) != 2 {
+		t.Fatalf("got %d shares, want 2: %+v", len(ages), ages)
+	}
+	d := ages[0]
+	if d.Share != `D:\` || d.Files != 4 || d.NeverVerified != 2 || d.OldestPath != `D:\v\old.mkv` {
+		t.Fatalf("D: share: %+v", d)
+	}
+	if n := ages[1]; n.Share != `\\nas\anime` || n.NeverVerified != 1 || !n.Oldest.IsZero() {
+		t.Fatalf("UNC share: %+v", n)
+	}
+}
+
+func TestShareOf(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`\\192.168.1.1\anime\Show\ep01.mkv`, `\\192.168.1.1\anime`},
+		{`//nas/anime/ep01.mkv`, `\\nas\anime`},
+		{`d:\Videos\ep01.mkv`, `D:\`},
+		{`/mnt/anime/Show/ep01.mkv`, `/mnt/anime`},
+		{`/ep01.mkv`, `/`},
+		{`Show/ep01.mkv`, `Show`},
+		{`ep01.mkv`, `.`},
+	}
+	for _, tt := range tests {
+		if got := shareOf(tt.in); got != tt.want {
+			t.Errorf("shareOf(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
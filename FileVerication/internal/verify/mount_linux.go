@@ -0,0 +1,19 @@
+package verify
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// mountKey identifies the filesystem holding path by its device ID, so
+// files on one mount share slots whatever directory they are in. Paths
+// that cannot be stat'ed fall back to the share their name suggests.
+func mountKey(path string) string {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return strings.ToLower(shareOf(path))
+	}
+	return "dev:" + strconv.FormatUint(st.Dev, 10)
+}
@@ -0,0 +1,33 @@
+package verify
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestMountKey_Device(t *testing.T) {
+	tmp := writeFile(t, t.TempDir(), "a.mkv", []byte("a"))
+	src, err := filepath.Abs("mount_linux.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a, b unix.Stat_t
+	if err := unix.Stat(tmp, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Stat(src, &b); err != nil {
+		t.Fatal(err)
+	}
+	if same := mountKey(tmp) == mountKey(src); same != (a.Dev == b.Dev) {
+		t.Fatalf("%s and %s share a key: %v, same device: %v", tmp, src, same, a.Dev == b.Dev)
+	}
+	if got := mountKey(filepath.Dir(tmp)); got != mountKey(tmp) {
+		t.Fatalf("directory and file on one mount: %q vs %q", got, mountKey(tmp))
+	}
+	if got := mountKey(`\\NAS\anime\missing.mkv`); got != `\\nas\anime` {
+		t.Fatalf("missing path: got %q, want the share", got)
+	}
+}
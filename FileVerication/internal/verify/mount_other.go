@@ -0,0 +1,10 @@
+//go:build !linux
+
+package verify
+
+import "strings"
+
+// mountKey is the share the path names; only Linux looks up the device.
+func mountKey(path string) string {
+	return strings.ToLower(shareOf(path))
+}
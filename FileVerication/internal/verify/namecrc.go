@@ -0,0 +1,88 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// crcTag matches an 8-hex-digit CRC32 in square brackets or parentheses, as
+// fansub groups put it in release names: "[Group] Show - 01 [1080p][ABCD1234].mkv".
+var crcTag = regexp.MustCompile(`\[([0-9A-Fa-f]{8})\]|\(([0-9A-Fa-f]{8})\)`)
+
+// FilenameCRC returns the CRC32 tag in the file name of path, upper-cased.
+// When a name carries several, the last one is the checksum. Tags that read
+// as a date, such as [20240115], are ignored; other all-digit tags are CRCs.
+func FilenameCRC(path string) (string, bool) {
+	name := path
+	if i := strings.LastIndexAny(name, `\/`); i >= 0 {
+		name = name[i+1:]
+	}
+	m := crcTag.FindAllStringSubmatch(name, -1)
+	for i := len(m) - 1; i >= 0; i-- {
+		tag := m[i][1] + m[i][2]
+		if !isDate(tag) {
+			return strings.ToUpper(tag), true
+		}
+	}
+	return "", false
+}
+
+// isDate reports whether tag is a YYYYMMDD date from 1980 to 2100.
+func isDate(tag string) bool {
+	t, err := time.Parse("20060102", tag)
+	return err == nil && t.Year() >= 1980 && t.Year() <= 2100
+}
+
+// OutcomeNoTag is what CheckFilenameCRC reports for a file name without a
+// CRC32 tag to check against. VerifyStream never produces it.
+const OutcomeNoTag Outcome = "no_tag"
+
+// CheckFilenameCRC hashes fi with CRC32 and compares the result with the
+// tag in its file name. The Record's Expected is the tag; files without
+// one are not read and come back as OutcomeNoTag.
+func CheckFilenameCRC(ctx context.Context, fi index.FileItem, onProgress func(n int64)) Record {
+	started := time.Now()
+	rec := Record{Path: fi.Path, Length: fi.Length}
+	local := fi.FSPath()
+	if local != fi.Path {
+		rec.LocalPath = local
+	}
+	finish := func(outcome Outcome, err error) Record {
+		rec.Outcome = outcome
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		rec.Duration = time.Since(started)
+		return rec
+	}
+
+	tag, ok := FilenameCRC(fi.Path)
+	if !ok {
+		return finish(OutcomeNoTag, nil)
+	}
+	rec.Expected = tag
+
+	info, err := os.Stat(local)
+	if err != nil {
+		return finish(OutcomeStatError, err)
+	}
+	n := info.Size()
+	rec.ActualLength = &n
+	if rec.Length == 0 {
+		rec.Length = n
+	}
+
+	rec.Computed, err = FileHashHexContext(ctx, local, "CRC32", onProgress)
+	switch {
+	case err != nil:
+		return finish(OutcomeHashError, err)
+	case rec.Computed != tag:
+		rec.Disagreed = []string{"CRC32"}
+		return finish(OutcomeMismatch, nil)
+	}
+	return finish(OutcomeOK, nil)
+}
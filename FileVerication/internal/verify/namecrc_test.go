@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilenameCRC(t *testing.T) {
+	tests := []struct {
+		path string
+		tag  string
+		ok   bool
+	}{
+		{"[Group] Show - 01 [1080p][ABCD1234].mkv", "ABCD1234", true},
+		{"[Group] Show - 01 (cbf43926).mkv", "CBF43926", true},
+		{`\\nas\anime\[DEADBEEF]\Show - 01.mkv`, "", false},
+		{"/mnt/[DEADBEEF]/Show - 01 [1080p] [0badf00d].mkv", "0BADF00D", true},
+		{"Show - 01 [1080p].mkv", "", false},
+		{"Show - 01 [ABCD123].mkv", "", false},
+		{"Show - 01 [ABCD12345].mkv", "", false},
+		{"Show - 01 ABCD1234.mkv", "", false},
+		{"Show - 01 (ABCD1234].mkv", "", false},
+		{"Show - 01 [ABCD1234).mkv", "", false},
+		{"[Group] Show - 01 [20240115].mkv", "", false},
+		{"[Group] Show - 01 [ABCD1234] [20240115].mkv", "ABCD1234", true},
+		{"Show - 01 [12345678].mkv", "12345678", true},
+		{"Show - 01 [20241399].mkv", "20241399", true},
+		{"Show - 01 [00000000].mkv", "00000000", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tag, ok := FilenameCRC(tt.path)
+			if tag != tt.tag || ok != tt.ok {
+				t.Errorf("FilenameCRC(%q) = %q, %v; want %q, %v", tt.path, tag, ok, tt.tag, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckFilenameCRC(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("123456789"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		outcome Outcome
+	}{
+		{"match", write("Show - 01 [CBF43926].mkv"), OutcomeOK},
+		{"lower-case tag", write("Show - 02 [cbf43926].mkv"), OutcomeOK},
+		{"mismatch", write("Show - 03 [00000000].mkv"), OutcomeMismatch},
+		{"no tag", write("Show - 04.mkv"), OutcomeNoTag},
+		{"missing", filepath.Join(dir, "Show - 05 [CBF43926].mkv"), OutcomeStatError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := CheckFilenameCRC(context.Background(), index.FileItem{Path: tt.path}, nil)
+			if rec.Outcome != tt.outcome {
+				t.Fatalf("outcome = %s (%s), want %s", rec.Outcome, rec.Error, tt.outcome)
+			}
+			if tt.outcome == OutcomeOK || tt.outcome == OutcomeMismatch {
+				if rec.Computed != "CBF43926" || rec.Length != 9 {
+					t.Errorf("computed %q length %d, want CBF43926 length 9", rec.Computed, rec.Length)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Defaults for Options.BufferSize and Options.QueueDepth.
+const (
+	DefaultBufferSize = 4 << 20
+	DefaultQueueDepth = 4
+)
+
+// readAhead returns the buffer size and queue depth of the read-ahead
+// pipeline, filling in defaults and a 64 KiB floor on the buffer size.
+func (o Options) readAhead() (bufferSize, queueDepth int) {
+	bufferSize, queueDepth = o.BufferSize, o.QueueDepth
+	switch {
+	case bufferSize == 0:
+		bufferSize = DefaultBufferSize
+	case bufferSize < 64<<10:
+		bufferSize = 64 << 10
+	}
+	if queueDepth < 1 {
+		queueDepth = DefaultQueueDepth
+	}
+	return bufferSize, queueDepth
+}
+
+// bufPool keeps read buffers across files; buffers smaller than the
+// current BufferSize are dropped.
+var bufPool sync.Pool
+
+func getBuf(size int) []byte {
+	if b, ok := bufPool.Get().(*[]byte); ok && cap(*b) >= size {
+		return (*b)[:size]
+	}
+	return make([]byte, size)
+}
+
+func putBuf(b []byte) {
+	bufPool.Put(&b)
+}
+
+type chunk struct {
+	buf []byte
+	n   int
+	off int64
+	err error
+}
+
+// hashFile feeds length bytes of f starting at start into h, or everything
+// from start to EOF when length is negative, and returns the number of
+// bytes hashed. Reads wait on opts.ReadLimit and stop once ctx is done. On Linux
+// the kernel is told the file is read sequentially and each hashed range is
+// dropped from the page cache, so a verify run does not evict everything
+// else.
+func hashFile(ctx context.Context, f *os.File, start, length int64, h io.Writer, opts Options, onProgress func(n int64)) (int64, error) {
+	bufferSize, queueDepth := opts.readAhead()
+	adviseSequential(f, start, length)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	free := make(chan []byte, queueDepth)
+	for range queueDepth {
+		free <- getBuf(bufferSize)
+	}
+	filled := make(chan chunk, queueDepth)
+	// waitErr is set when the limit gives up on a read; that read's data was
+	// never allowed and is not hashed.
+	var waitErr error
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(filled)
+		pos := start
+		for length < 0 || pos < start+length {
+			if ctx.Err() != nil {
+				return
+			}
+			var buf []byte
+			select {
+			case buf = <-free:
+			case <-ctx.Done():
+				return
+			}
+			want := len(buf)
+			if length >= 0 && int64(want) > start+length-pos {
+				want = int(start + length - pos)
+			}
+			n, err := f.ReadAt(buf[:want], pos)
+			if n > 0 {
+				if werr := opts.ReadLimit.wait(ctx, n); werr != nil {
+					waitErr = werr
+					free <- buf
+					return
+				}
+			}
+			if err == io.EOF && (length < 0 || pos+int64(n) == start+length) {
+				err = nil
+				if n == 0 {
+					free <- buf
+					return
+				}
+			}
+			filled <- chunk{buf: buf, n: n, off: pos, err: err}
+			if err != nil {
+				return
+			}
+			pos += int64(n)
+		}
+	}()
+
+	defer func() {
+		cancel()
+		wg.Wait()
+		for c := range filled {
+			putBuf(c.buf)
+		}
+		close(free)
+		for b := range free {
+			putBuf(b)
+		}
+	}()
+
+	var hashed int64
+	for c := range filled {
+		if c.n > 0 {
+			if _, err := h.Write(c.buf[:c.n]); err != nil {
+				free <- c.buf
+				return hashed, err
+			}
+			hashed += int64(c.n)
+			adviseDontNeed(f, c.off, int64(c.n))
+			if onProgress != nil {
+				onProgress(int64(c.n))
+			}
+		}
+		free <- c.buf
+		switch {
+		case c.err == io.EOF:
+			return hashed, fmt.Errorf("unexpected EOF at offset %d (wanted %d bytes total)", c.off+int64(c.n), length)
+		case c.err != nil:
+			if err := ctx.Err(); err != nil {
+				return hashed, err
+			}
+			return hashed, c.err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return hashed, err
+	}
+	return hashed, waitErr
+}
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashFile_Pipeline(t *testing.T) {
+	dir := t.TempDir()
+	content := makeTestData(5<<20 + 12345)
+	p := writeFile(t, dir, "file.bin", content)
+
+	tests := []struct {
+		name          string
+		opts          Options
+		start, length int64
+		wantErr       string
+	}{
+		{"whole file, small buffers", Options{BufferSize: 64 << 10, QueueDepth: 1}, 0, -1, ""},
+		{"whole file, default", Options{BufferSize: 4 << 20, QueueDepth: 4}, 0, -1, ""},
+		{"whole file, buffer larger than file", Options{BufferSize: 16 << 20, QueueDepth: 2}, 0, -1, ""},
+		{"range", Options{BufferSize: 64 << 10, QueueDepth: 3}, 1000, 2 << 20, ""},
+		{"range to end", Options{BufferSize: 1 << 20, QueueDepth: 2}, 1 << 20, int64(len(content)) - 1<<20, ""},
+		{"range past end", Options{BufferSize: 1 << 20, QueueDepth: 2}, 1 << 20, int64(len(content)), "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+
+			h := sha256.New()
+			var progress int64
+			n, err := hashFile(t.Context(), f, tt.start, tt.length, h, tt.opts, func(n int64) { progress += n })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := content[tt.start:]
+			if tt.length >= 0 {
+				want = want[:tt.length]
+			}
+			if n != int64(len(want)) || progress != n {
+				t.Fatalf("hashed %d bytes, progress %d, want %d", n, progress, len(want))
+			}
+			if sum := sha256.Sum256(want); string(h.Sum(nil)) != string(sum[:]) {
+				t.Fatalf("hash differs")
+			}
+		})
+	}
+}
+
+func TestHashFile_StopsWhenLimitGivesUp(t *testing.T) {
+	p := writeFile(t, t.TempDir(), "file.bin", makeTestData(8*mib))
+
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	// The first MiB is burst; the second would not be allowed before the
+	// deadline and must not be hashed.
+	ctx, cancel := context.WithTimeout(t.Context(), 250*time.Millisecond)
+	defer cancel()
+	var progress int64
+	n, err := hashFile(ctx, f, 0, -1, sha256.New(), Options{ReadLimit: NewThrottle(2, 1), BufferSize: mib, QueueDepth: 4}, func(n int64) { progress += n })
+	if err == nil {
+		t.Fatal("hashFile finished despite the deadline")
+	}
+	if n != mib || progress != n {
+		t.Fatalf("hashed %d bytes, progress %d, want %d", n, progress, mib)
+	}
+}
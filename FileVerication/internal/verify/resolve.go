@@ -0,0 +1,115 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Resolver finds files whose recorded path differs from the name on disk
+// only in case or Unicode normalization (NFC vs NFD), as happens when the
+// Windows indexer and another client disagree. Directory listings are read
+// once and cached, so it is cheap to use for many files under one tree.
+type Resolver struct {
+	mu   sync.Mutex
+	dirs map[string]*dirListing
+}
+
+// dirListing is one directory's entries by fold key, read once. The read
+// happens outside Resolver.mu so workers listing different directories do
+// not wait on each other.
+type dirListing struct {
+	once    sync.Once
+	entries map[string][]string // nil if the directory could not be read
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{dirs: map[string]*dirListing{}}
+}
+
+// Resolve walks p one component at a time, matching each against the
+// parent's listing. An exact name wins over an NFC match, which wins over a
+// case-insensitive one. It returns the path as it exists on disk, or false
+// if some component has no match.
+func (r *Resolver) Resolve(p string) (string, bool) {
+	p = filepath.Clean(p)
+	vol := filepath.VolumeName(p)
+	rest := p[len(vol):]
+
+	cur := vol
+	if strings.HasPrefix(rest, string(filepath.Separator)) {
+		cur += string(filepath.Separator)
+	} else if cur == "" {
+		cur = "."
+	}
+
+	for _, comp := range strings.Split(strings.Trim(rest, string(filepath.Separator)), string(filepath.Separator)) {
+		if comp == "" || comp == "." {
+			continue
+		}
+		name, ok := r.match(cur, comp)
+		if !ok {
+			return "", false
+		}
+		cur = filepath.Join(cur, name)
+	}
+	if _, err := os.Stat(cur); err != nil {
+		return "", false
+	}
+	return cur, true
+}
+
+func (r *Resolver) match(dir, comp string) (string, bool) {
+	entries, ok := r.listing(dir)
+	if !ok {
+		return "", false
+	}
+	candidates := entries[foldKey(comp)]
+	if len(candidates) == 0 {
+		return "", false
+	}
+	nfc := norm.NFC.String(comp)
+	for _, name := range candidates {
+		if name == comp {
+			return name, true
+		}
+	}
+	for _, name := range candidates {
+		if norm.NFC.String(name) == nfc {
+			return name, true
+		}
+	}
+	return candidates[0], true
+}
+
+func (r *Resolver) listing(dir string) (map[string][]string, bool) {
+	r.mu.Lock()
+	l := r.dirs[dir]
+	if l == nil {
+		l = &dirListing{}
+		r.dirs[dir] = l
+	}
+	r.mu.Unlock()
+
+	l.once.Do(func() {
+		des, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		l.entries = make(map[string][]string, len(des))
+		for _, de := range des {
+			k := foldKey(de.Name())
+			l.entries[k] = append(l.entries[k], de.Name())
+		}
+	})
+	return l.entries, l.entries != nil
+}
+
+// foldKey is the comparison key for a name: NFC, then Unicode case folding.
+func foldKey(name string) string {
+	return cases.Fold().String(norm.NFC.String(name))
+}
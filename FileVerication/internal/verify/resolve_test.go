@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestResolver_CaseAndNormalization(t *testing.T) {
+	root := t.TempDir()
+	// The directory is stored decomposed (NFD) on disk.
+	dir := filepath.Join(root, norm.NFD.String("Shōnen Ālbum"))
+	if err := os.Mkdir(dir, 0o750); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	onDisk := writeFile(t, dir, "EP01.mkv", []byte("episode"))
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+		ok   bool
+	}{
+		{"exact", onDisk, onDisk, true},
+		{"nfc", filepath.Join(root, norm.NFC.String("Shōnen Ālbum"), "EP01.mkv"), onDisk, true},
+		{"case", filepath.Join(root, norm.NFC.String("SHŌNEN ĀLBUM"), "ep01.MKV"), onDisk, true},
+		{"missing", filepath.Join(root, "Shonen Album", "EP01.mkv"), "", false},
+	}
+	r := NewResolver()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Resolve(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("Resolve(%q): got %q %v want %q %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerify_ResolveFallback(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("fallback")
+	onDisk := writeFile(t, dir, "Ep01.mkv", content)
+	hash, err := hashHexUpper("SHA256", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []index.FileItem{{Ok: true, Path: filepath.Join(dir, "EP01.MKV"), Length: int64(len(content)), Hash: hash}}
+
+	for _, fallback := range []bool{false, true} {
+		stats := &metrics.Stats{}
+		res := Verify(context.Background(), "SHA256", items, Options{Workers: 1, ResolveFallback: fallback}, stats, nil)
+		if !fallback {
+			if stats.StatErrors != 1 || stats.Resolved != 0 {
+				t.Fatalf("without fallback: stat_errors=%d resolved=%d", stats.StatErrors, stats.Resolved)
+			}
+			continue
+		}
+		if stats.StatErrors != 0 || stats.Resolved != 1 || stats.OK != 1 {
+			t.Fatalf("with fallback: stat_errors=%d resolved=%d ok=%d", stats.StatErrors, stats.Resolved, stats.OK)
+		}
+		if len(res.Resolved) != 1 || res.Resolved[0].ResolvedPath != onDisk {
+			t.Fatalf("Resolved mismatch: %+v", res.Resolved)
+		}
+	}
+
+	// A mismatch names the file that was actually hashed.
+	items[0].Hash = "BAD"
+	res := Verify(context.Background(), "SHA256", items, Options{Workers: 1, ResolveFallback: true}, &metrics.Stats{}, nil)
+	if len(res.Mismatches) != 1 || res.Mismatches[0].LocalPath != onDisk {
+		t.Fatalf("Mismatches: %+v", res.Mismatches)
+	}
+}
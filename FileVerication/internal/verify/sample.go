@@ -0,0 +1,98 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"cmp"
+	"container/heap"
+	"encoding/binary"
+	"hash/fnv"
+	"iter"
+	"math"
+	"slices"
+)
+
+// SampleOptions selects a random subset of an index to verify. Selection is
+// a function of the seed and each item's path only, so the same seed picks
+// the same files however the index is ordered, and a different seed each
+// night eventually covers everything.
+type SampleOptions struct {
+	// Percent keeps roughly this share of items, 0-100.
+	Percent float64
+	// Count keeps exactly this many items (or all, if fewer).
+	Count int
+	Seed  uint64
+}
+
+func (o SampleOptions) Enabled() bool {
+	return o.Percent > 0 || o.Count > 0
+}
+
+// Keep reports whether fi falls into a Percent sample. It can be called as
+// items stream by; Count samples need SampleCount instead.
+func (o SampleOptions) Keep(fi index.FileItem) bool {
+	if o.Percent <= 0 || o.Percent >= 100 {
+		return true
+	}
+	return float64(sampleKey(o.Seed, fi.Path)) < o.Percent/100*math.MaxUint64
+}
+
+// SampleCount returns the n items with the lowest sample keys, in key order,
+// which is effectively a random order. It keeps at most n items in memory.
+func SampleCount(items iter.Seq[index.FileItem], n int, seed uint64) []index.FileItem {
+	if n <= 0 {
+		return nil
+	}
+	h := &sampleHeap{}
+	for fi := range items {
+		k := sampleKey(seed, fi.Path)
+		if h.Len() < n {
+			heap.Push(h, keyed{k, fi})
+		} else if k < (*h)[0].key {
+			(*h)[0] = keyed{k, fi}
+			heap.Fix(h, 0)
+		}
+	}
+
+	picked := slices.Clone(*h)
+	slices.SortFunc(picked, func(a, b keyed) int { return cmp.Compare(a.key, b.key) })
+	out := make([]index.FileItem, len(picked))
+	for i, kv := range picked {
+		out[i] = kv.fi
+	}
+	return out
+}
+
+func sampleKey(seed uint64, path string) uint64 {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], seed)
+	_, _ = h.Write(b[:])
+	_, _ = h.Write([]byte(index.PathKey(path)))
+	// FNV's low bits mix poorly for similar inputs; finish with splitmix64.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+type keyed struct {
+	key uint64
+	fi  index.FileItem
+}
+
+// sampleHeap is a max-heap on key, so the root is the first to evict.
+type sampleHeap []keyed
+
+func (h sampleHeap) Len() int           { return len(h) }
+func (h sampleHeap) Less(i, j int) bool { return h[i].key > h[j].key }
+func (h sampleHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x any)        { *h = append(*h, x.(keyed)) }
+func (h *sampleHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
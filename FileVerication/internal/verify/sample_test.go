@@ -0,0 +1,96 @@
+package verify
+
+import (
+	"FileVerication/internal/index"
+	"FileVerication/internal/metrics"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func sampleItems(n int) []index.FileItem {
+	items := make([]index.FileItem, n)
+	for i := range items {
+		items[i] = index.FileItem{Ok: true, Path: fmt.Sprintf(`D:\Videos\%05d.mkv`, i), Length: 1}
+	}
+	return items
+}
+
+func samplePaths(items []index.FileItem) []string {
+	paths := make([]string, len(items))
+	for i, fi := range items {
+		paths[i] = fi.Path
+	}
+	return paths
+}
+
+func TestSampleCount_Reproducible(t *testing.T) {
+	items := sampleItems(1000)
+	reversed := slices.Clone(items)
+	slices.Reverse(reversed)
+
+	a := samplePaths(SampleCount(slices.Values(items), 25, 7))
+	b := samplePaths(SampleCount(slices.Values(reversed), 25, 7))
+	if len(a) != 25 {
+		t.Fatalf("got %d items, want 25", len(a))
+	}
+	if !slices.Equal(a, b) {
+		t.Fatalf("sample depends on index order:\n%v\n%v", a, b)
+	}
+	if c := samplePaths(SampleCount(slices.Values(items), 25, 8)); slices.Equal(a, c) {
+		t.Fatalf("different seeds picked the same sample")
+	}
+	if got := SampleCount(slices.Values(items[:10]), 25, 7); len(got) != 10 {
+		t.Fatalf("got %d items from 10, want all", len(got))
+	}
+}
+
+func TestSampleOptions_KeepPercent(t *testing.T) {
+	tests := []struct {
+		percent float64
+		lo, hi  int
+	}{
+		{0, 10000, 10000},
+		{100, 10000, 10000},
+		{10, 900, 1100},
+		{50, 4800, 5200},
+	}
+	items := sampleItems(10000)
+	for _, tt := range tests {
+		o := SampleOptions{Percent: tt.percent, Seed: 3}
+		kept := 0
+		for _, fi := range items {
+			if o.Keep(fi) {
+				kept++
+			}
+		}
+		if kept < tt.lo || kept > tt.hi {
+			t.Errorf("Percent %v kept %d of %d, want %d-%d", tt.percent, kept, len(items), tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestNewCoverage(t *testing.T) {
+	s := metrics.Snapshot{OK: 3000, BytesHashed: 500}
+	c := NewCoverage(10000, 1000, s, 0.001)
+	if c.VerifiedFiles != 3000 || c.FileFraction != 0.3 || c.ByteFraction != 0.5 {
+		t.Fatalf("fractions: %+v", c)
+	}
+	// With no failures the bound is 1-(0.05)^(1/n), roughly 3/n.
+	if want := 1 - math.Pow(0.05, 1.0/3000); math.Abs(c.UpperBound95-want) > 1e-9 {
+		t.Fatalf("UpperBound95 = %v, want %v", c.UpperBound95, want)
+	}
+	if want := 1 - math.Pow(0.999, 3000); math.Abs(c.Confidence-want) > 1e-9 {
+		t.Fatalf("Confidence = %v, want %v", c.Confidence, want)
+	}
+
+	s.HashMismatches = 3
+	c = NewCoverage(10000, 1000, s, 0.001)
+	if c.Corrupt != 3 {
+		t.Fatalf("Corrupt = %d, want 3", c.Corrupt)
+	}
+	if got := binomCDF(3, c.VerifiedFiles, c.UpperBound95); math.Abs(got-0.05) > 1e-6 {
+		t.Fatalf("CDF at upper bound = %v, want 0.05", got)
+	}
+}
@@ -0,0 +1,105 @@
+package verify
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const mib = 1 << 20
+
+// Throttle limits the rate at which files are read for hashing, in MB/s
+// (MiB/s) with a burst allowance. The rate can be changed while files are
+// being read; the new rate applies from the next read.
+type Throttle struct {
+	lim *rate.Limiter
+}
+
+// NewThrottle returns a Throttle at mbps MB/s allowing bursts of burstMB.
+// mbps <= 0 means unlimited. The burst is at least 1 MB.
+func NewThrottle(mbps, burstMB float64) *Throttle {
+	t := &Throttle{lim: rate.NewLimiter(rate.Inf, 0)}
+	t.SetBurst(burstMB)
+	t.SetRate(mbps)
+	return t
+}
+
+func (t *Throttle) SetRate(mbps float64) {
+	if mbps <= 0 {
+		t.lim.SetLimit(rate.Inf)
+		return
+	}
+	t.lim.SetLimit(rate.Limit(mbps * mib))
+}
+
+// Rate returns the current limit in MB/s, or 0 when unlimited.
+func (t *Throttle) Rate() float64 {
+	l := t.lim.Limit()
+	if l == rate.Inf {
+		return 0
+	}
+	return float64(l) / mib
+}
+
+func (t *Throttle) SetBurst(burstMB float64) {
+	t.lim.SetBurst(int(math.Max(burstMB*mib, mib)))
+}
+
+// wait blocks until n more bytes may be read, or ctx is done. A nil
+// Throttle never waits.
+func (t *Throttle) wait(ctx context.Context, n int) error {
+	if t == nil || t.lim.Limit() == rate.Inf {
+		return nil
+	}
+	for n > 0 {
+		chunk := min(n, t.lim.Burst())
+		if err := t.lim.WaitN(ctx, chunk); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// mountSlots caps how many files are hashed at once from each mount, so
+// workers sharing a disk take turns instead of seeking between files.
+type mountSlots struct {
+	per int
+	mu  sync.Mutex
+	sem map[string]chan struct{}
+}
+
+func newMountSlots(per int) *mountSlots {
+	if per <= 0 {
+		return nil
+	}
+	return &mountSlots{per: per, sem: map[string]chan struct{}{}}
+}
+
+// acquire takes a slot on the mount holding path and returns the function
+// that gives it back.
+func (m *mountSlots) acquire(ctx context.Context, path string) (func(), error) {
+	if m == nil {
+		return func() {}, nil
+	}
+	key := mountKey(path)
+	m.mu.Lock()
+	sem := m.sem[key]
+	if sem == nil {
+		sem = make(chan struct{}, m.per)
+		m.sem[key] = sem
+	}
+	m.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
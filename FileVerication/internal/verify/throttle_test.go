@@ -0,0 +1,71 @@
+package verify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottle_LimitsHashing(t *testing.T) {
+	dir := t.TempDir()
+	p := writeFile(t, dir, "file.bin", makeTestData(3*mib))
+	hash := func(limit *Throttle) time.Duration {
+		t.Helper()
+		start := time.Now()
+		if _, err := fileHashes(t.Context(), p, []string{"SHA256"}, Options{ReadLimit: limit}, nil); err != nil {
+			t.Fatal(err)
+		}
+		return time.Since(start)
+	}
+
+	limit := NewThrottle(8, 1)
+	if got := limit.Rate(); got != 8 {
+		t.Fatalf("Rate() = %v, want 8", got)
+	}
+	// The first MiB is burst; the other two take 250ms at 8 MiB/s.
+	if d := hash(limit); d < 200*time.Millisecond {
+		t.Fatalf("hashed 3 MiB at 8 MiB/s in %v", d)
+	}
+
+	limit.SetRate(0)
+	if got := limit.Rate(); got != 0 {
+		t.Fatalf("Rate() after unlimiting = %v, want 0", got)
+	}
+	if d := hash(limit); d > 200*time.Millisecond {
+		t.Fatalf("unlimited hash took %v", d)
+	}
+	if d := hash(nil); d > 200*time.Millisecond {
+		t.Fatalf("hash without a limit took %v", d)
+	}
+}
+
+func TestMountSlots(t *testing.T) {
+	m := newMountSlots(1)
+	release, err := m.acquire(context.Background(), `\\nas\anime\a.mkv`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := m.acquire(context.Background(), `\\nas\music\a.flac`)
+	if err != nil {
+		t.Fatalf("other share blocked: %v", err)
+	}
+	other()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := m.acquire(ctx, `\\NAS\anime\b.mkv`); err != context.DeadlineExceeded {
+		t.Fatalf("second slot on same share: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	again, err := m.acquire(context.Background(), `\\nas\anime\b.mkv`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again()
+
+	if _, err := newMountSlots(0).acquire(context.Background(), "x"); err != nil {
+		t.Fatalf("no cap: %v", err)
+	}
+}
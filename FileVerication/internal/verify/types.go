@@ -1,17 +1,59 @@
 package verify
 
+import "time"
+
 type Mismatch struct {
-	Path     string
-	Expected string
-	Computed string
+	Path string
+	// LocalPath is the mapped path that was hashed, if it differs from Path.
+	LocalPath string
+	Expected  string
+	Computed  string
+	// Disagreed lists the algorithms whose digest did not match the index.
+	Disagreed []string
+}
+
+// Resolution records a file that was only found through the fallback
+// resolver, under a name differing in case or Unicode normalization.
+type Resolution struct {
+	Path         string
+	ResolvedPath string
 }
 
 type Result struct {
 	Mismatches []Mismatch
+	// Records holds one entry per file that did not verify OK, whatever
+	// the reason, in completion order.
+	Records  []Record
+	Resolved []Resolution
+	// Canceled is set when the context ended the run before all items were
+	// verified; the other fields then cover only part of the index.
+	Canceled bool
+	// BudgetExhausted is set when MaxBytes or MaxDuration ended the run.
+	BudgetExhausted bool
 }
 
 type Options struct {
 	Workers int
+	// ResolveFallback retries paths that fail to stat with a Resolver.
+	ResolveFallback bool
+	// OnRecord, if set, is called from the workers as each file completes.
+	OnRecord func(Record)
+	// MaxBytes and MaxDuration, when positive, stop the run once that many
+	// bytes have been hashed or that much time has passed.
+	MaxBytes    int64
+	MaxDuration time.Duration
+	// PerMount, when positive, caps how many files are hashed at once from
+	// the same share or drive.
+	PerMount int
+	// ReadLimit, if set, limits the rate files are read at across all
+	// workers. The caller may change its rate while the run is going.
+	ReadLimit *Throttle
+	// BufferSize and QueueDepth tune the read-ahead pipeline: a reader
+	// fills up to QueueDepth buffers of BufferSize bytes while the hasher
+	// consumes them, so network latency and hashing overlap. Zero means
+	// DefaultBufferSize and DefaultQueueDepth.
+	BufferSize int
+	QueueDepth int
 }
 
 type SplitDiff struct {
@@ -4,29 +4,123 @@ import (
 	"FileVerication/internal/index"
 	"FileVerication/internal/metrics"
 	"FileVerication/internal/progress"
+	"context"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
-func Verify(runAlgorithm string, items []index.FileItem, opts Options, stats *metrics.Stats, bar *progress.Bar) *Result {
+func Verify(ctx context.Context, runAlgorithm string, items []index.FileItem, opts Options, stats *metrics.Stats, bar *progress.Bar) *Result {
+	// Workers may stop early on a budget; sending stops when they return.
+	sendCtx, stopSending := context.WithCancel(ctx)
+	defer stopSending()
+	jobs := make(chan index.FileItem)
+	go func() {
+		defer close(jobs)
+		for _, fi := range items {
+			select {
+			case jobs <- fi:
+			case <-sendCtx.Done():
+				return
+			}
+		}
+	}()
+	return VerifyStream(ctx, runAlgorithm, jobs, opts, stats, bar)
+}
+
+// VerifyStream verifies items as they arrive on jobs, so hashing can start
+// while the index is still being loaded. It returns once jobs is closed and
+// every received item has been processed, or once ctx is done. Cancelling
+// ctx stops workers from taking new items and aborts files being hashed;
+// those are left out of the stats and the result is marked Canceled. The
+// sender must stop sending on cancellation too, and also once VerifyStream
+// returns, since a budget in opts can end the run before jobs is drained.
+func VerifyStream(ctx context.Context, runAlgorithm string, jobs <-chan index.FileItem, opts Options, stats *metrics.Stats, bar *progress.Bar) *Result {
 	workers := opts.Workers
 	if workers <= 0 {
 		workers = 1
 	}
 	res := &Result{}
+	var resolver *Resolver
+	if opts.ResolveFallback {
+		resolver = NewResolver()
+	}
+	slots := newMountSlots(opts.PerMount)
 	var mu sync.Mutex
-
-	jobs := make(chan index.FileItem)
 	var wg sync.WaitGroup
 
+	// Budgets stop workers from taking new files; files already being
+	// hashed are finished.
+	var hashed atomic.Int64
+	var deadline time.Time
+	if opts.MaxDuration > 0 {
+		deadline = time.Now().Add(opts.MaxDuration)
+	}
+	var exhausted atomic.Bool
+	overBudget := func() bool {
+		if (opts.MaxBytes > 0 && hashed.Load() >= opts.MaxBytes) ||
+			(!deadline.IsZero() && time.Now().After(deadline)) {
+			exhausted.Store(true)
+		}
+		return exhausted.Load()
+	}
+
 	worker := func() {
 		defer wg.Done()
 
-		for fi := range jobs {
-			finish := func() {
+		for {
+			if overBudget() {
+				return
+			}
+			var fi index.FileItem
+			select {
+			case <-ctx.Done():
+				return
+			case next, ok := <-jobs:
+				if !ok {
+					return
+				}
+				fi = next
+			}
+
+			started := time.Now()
+			local := fi.FSPath()
+			var size *int64
+			var disagree []string
+			want := fi.Hash
+			finish := func(outcome Outcome, computed string, err error) {
 				atomic.AddInt64(&stats.Processed, 1)
+				rec := Record{
+					Path:         fi.Path,
+					Outcome:      outcome,
+					Length:       fi.Length,
+					ActualLength: size,
+					Expected:     want,
+					Computed:     computed,
+					Duration:     time.Since(started),
+					Disagreed:    disagree,
+				}
+				if local != fi.Path {
+					rec.LocalPath = local
+				}
+				if err != nil {
+					rec.Error = err.Error()
+				} else if outcome == OutcomeSkipped && fi.Error != nil {
+					rec.Error = *fi.Error
+				}
+				if outcome != OutcomeOK {
+					mu.Lock()
+					res.Records = append(res.Records, rec)
+					mu.Unlock()
+				}
+				if opts.OnRecord != nil {
+					opts.OnRecord(rec)
+				}
 			}
 			advance := func(n int64) {
 				if n > 0 && bar != nil {
@@ -37,59 +131,105 @@ func Verify(runAlgorithm string, items []index.FileItem, opts Options, stats *me
 			if fi.Error != nil {
 				atomic.AddInt64(&stats.Skipped, 1)
 				advance(fi.Length)
-				finish()
+				finish(OutcomeSkipped, "", nil)
 				continue
 			}
 
-			info, err := os.Stat(fi.Path)
+			info, err := os.Stat(local)
+			if err != nil && resolver != nil {
+				if found, ok := resolver.Resolve(local); ok {
+					local = found
+					info, err = os.Stat(local)
+					if err == nil {
+						atomic.AddInt64(&stats.Resolved, 1)
+						mu.Lock()
+						res.Resolved = append(res.Resolved, Resolution{Path: fi.Path, ResolvedPath: local})
+						mu.Unlock()
+					}
+				}
+			}
 			if err != nil {
 				atomic.AddInt64(&stats.StatErrors, 1)
 				advance(fi.Length)
-				finish()
+				finish(OutcomeStatError, "", err)
 				continue
 			}
+			n := info.Size()
+			size = &n
 			if info.Size() != fi.Length {
 				atomic.AddInt64(&stats.SizeMismatches, 1)
 				advance(fi.Length)
-				finish()
+				finish(OutcomeSizeMismatch, "", fmt.Errorf("size is %d, index has %d", info.Size(), fi.Length))
 				continue
 			}
 
 			atomic.AddInt64(&stats.BytesStatOK, info.Size())
 
+			release, err := slots.acquire(ctx, local)
+			if err != nil {
+				return
+			}
+			// Every digest the index has for the file is checked in one read.
+			// The run's algorithm may come from Hash or from Hashes; an item
+			// with neither still gets it computed, and disagrees.
+			primary := index.AlgorithmKey(runAlgorithm)
+			expected := fi.Digests(runAlgorithm)
+			if _, ok := expected[primary]; !ok {
+				expected[primary] = ""
+			}
+			want = expected[primary]
+			algorithms := slices.Sorted(maps.Keys(expected))
+
 			var bytesSent int64
-			computed, err := FileHashHex(fi.Path, runAlgorithm, func(n int64) {
+			digests, err := fileHashes(ctx, local, algorithms, opts, func(n int64) {
 				atomic.AddInt64(&stats.BytesHashed, n)
+				hashed.Add(n)
 				bytesSent += n
 				advance(n)
 			})
+			release()
+			if err != nil && ctx.Err() != nil {
+				return
+			}
 			if err != nil {
 				atomic.AddInt64(&stats.HashErrors, 1)
 				advance(fi.Length - bytesSent)
-				finish()
+				finish(OutcomeHashError, "", err)
 				continue
 			}
 
 			advance(fi.Length - bytesSent)
 
-			match := strings.EqualFold(computed, strings.TrimSpace(fi.Hash))
-			if !match {
+			computed := digests[primary]
+			var disagreed []string
+			for _, alg := range algorithms {
+				if !strings.EqualFold(digests[alg], strings.TrimSpace(expected[alg])) {
+					disagreed = append(disagreed, alg)
+				}
+			}
+			if len(disagreed) > 0 {
 				atomic.AddInt64(&stats.HashMismatches, 1)
 
+				m := Mismatch{
+					Path:      fi.Path,
+					Expected:  want,
+					Computed:  computed,
+					Disagreed: disagreed,
+				}
+				if local != fi.Path {
+					m.LocalPath = local
+				}
 				mu.Lock()
-				res.Mismatches = append(res.Mismatches, Mismatch{
-					Path:     fi.Path,
-					Expected: fi.Hash,
-					Computed: computed,
-				})
+				res.Mismatches = append(res.Mismatches, m)
 				mu.Unlock()
 
-				finish()
+				disagree = disagreed
+				finish(OutcomeMismatch, computed, nil)
 				continue
 			}
 
 			atomic.AddInt64(&stats.OK, 1)
-			finish()
+			finish(OutcomeOK, computed, nil)
 		}
 	}
 
@@ -98,11 +238,8 @@ func Verify(runAlgorithm string, items []index.FileItem, opts Options, stats *me
 		go worker()
 	}
 
-	for _, fi := range items {
-		jobs <- fi
-	}
-	close(jobs)
-
 	wg.Wait()
+	res.Canceled = ctx.Err() != nil
+	res.BudgetExhausted = exhausted.Load()
 	return res
 }
@@ -4,6 +4,7 @@ import (
 	"FileVerication/internal/index"
 	"FileVerication/internal/metrics"
 	"bytes"
+	"context"
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
@@ -12,6 +13,7 @@ import (
 	"encoding/hex"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -222,7 +224,7 @@ func TestVerify_TableDriven(t *testing.T) {
 			stats := &metrics.Stats{}
 			atomic.StoreInt64(&stats.Total, int64(len(tt.items)))
 
-			res := Verify(tt.algorithm, tt.items, Options{Workers: tt.workers}, stats, nil)
+			res := Verify(context.Background(), tt.algorithm, tt.items, Options{Workers: tt.workers}, stats, nil)
 
 			got := want{
 				processed:      atomic.LoadInt64(&stats.Processed),
@@ -413,3 +415,208 @@ func TestCompareFileSplitsMany_TailDifference(t *testing.T) {
 		t.Fatalf("expected tail on file 1 only, got %v", res.TailBytes)
 	}
 }
+
+func TestVerify_HashesLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("mapped")
+	local := writeFile(t, dir, "ep01.mkv", content)
+	want, err := hashHexUpper("SHA256", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []index.FileItem{
+		{Ok: true, Path: `\\nas\anime\ep01.mkv`, LocalPath: local, Length: int64(len(content)), Hash: want},
+		{Ok: true, Path: `\\nas\anime\ep01.mkv`, LocalPath: local, Length: int64(len(content)), Hash: "BAD"},
+	}
+	stats := &metrics.Stats{}
+	res := Verify(context.Background(), "SHA256", items, Options{Workers: 1}, stats, nil)
+
+	if atomic.LoadInt64(&stats.OK) != 1 || atomic.LoadInt64(&stats.StatErrors) != 0 {
+		t.Fatalf("stats mismatch: ok=%d stat_errors=%d", stats.OK, stats.StatErrors)
+	}
+	if len(res.Mismatches) != 1 || res.Mismatches[0].Path != items[1].Path || res.Mismatches[0].LocalPath != local {
+		t.Fatalf("mismatches: %+v", res.Mismatches)
+	}
+}
+
+func TestVerify_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	content := makeTestData(4 << 20)
+	p := writeFile(t, dir, "big.bin", content)
+	hash, err := hashHexUpper("SHA256", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FileHashHexContext(ctx, p, "SHA256", nil); err != context.Canceled {
+		t.Fatalf("FileHashHexContext error: got %v want %v", err, context.Canceled)
+	}
+
+	items := make([]index.FileItem, 50)
+	for i := range items {
+		items[i] = index.FileItem{Ok: true, Path: p, Length: int64(len(content)), Hash: hash}
+	}
+	stats := &metrics.Stats{}
+	res := Verify(ctx, "SHA256", items, Options{Workers: 4}, stats, nil)
+	if !res.Canceled {
+		t.Fatalf("result not marked Canceled")
+	}
+	if n := atomic.LoadInt64(&stats.Processed); n >= int64(len(items)) {
+		t.Fatalf("processed %d items after cancellation", n)
+	}
+	if atomic.LoadInt64(&stats.HashErrors) != 0 {
+		t.Fatalf("aborted files counted as hash errors: %d", stats.HashErrors)
+	}
+}
+
+func TestVerify_RecordsEveryFailure(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("records")
+	p := writeFile(t, dir, "file.bin", content)
+	hash, err := hashHexUpper("SHA256", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := int64(len(content))
+	missing := filepath.Join(dir, "missing.bin")
+
+	items := []index.FileItem{
+		{Ok: true, Path: p, Length: n, Hash: hash},
+		{Ok: true, Path: p, Length: n, Hash: "WRONG"},
+		{Ok: true, Path: p, Length: n + 1, Hash: hash},
+		{Ok: true, Path: missing, Length: 3, Hash: "X"},
+		{Ok: false, Path: p, Length: n, Error: ptr("prior error")},
+	}
+	res := Verify(context.Background(), "SHA256", items, Options{Workers: 1}, &metrics.Stats{}, nil)
+
+	got := map[Outcome]Record{}
+	for _, rec := range res.Records {
+		got[rec.Outcome] = rec
+	}
+	if len(res.Records) != 4 || len(got) != 4 {
+		t.Fatalf("records mismatch: %+v", res.Records)
+	}
+	tests := []struct {
+		outcome  Outcome
+		path     string
+		actual   *int64
+		computed string
+		hasError bool
+	}{
+		{OutcomeMismatch, p, &n, hash, false},
+		{OutcomeSizeMismatch, p, &n, "", true},
+		{OutcomeStatError, missing, nil, "", true},
+		{OutcomeSkipped, p, nil, "", true},
+	}
+	for _, tt := range tests {
+		rec := got[tt.outcome]
+		if rec.Path != tt.path || rec.Computed != tt.computed || (rec.Error != "") != tt.hasError {
+			t.Fatalf("%s record mismatch: %+v", tt.outcome, rec)
+		}
+		if (rec.ActualLength == nil) != (tt.actual == nil) || (tt.actual != nil && *rec.ActualLength != *tt.actual) {
+			t.Fatalf("%s ActualLength mismatch: got %v want %v", tt.outcome, rec.ActualLength, tt.actual)
+		}
+	}
+}
+
+func TestVerify_MaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	content := makeTestData(64 << 10)
+	p := writeFile(t, dir, "file.bin", content)
+	hash, err := hashHexUpper("SHA256", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]index.FileItem, 20)
+	for i := range items {
+		items[i] = index.FileItem{Ok: true, Path: p, Length: int64(len(content)), Hash: hash}
+	}
+	stats := &metrics.Stats{}
+	res := Verify(context.Background(), "SHA256", items, Options{Workers: 1, MaxBytes: 3 * int64(len(content))}, stats, nil)
+	if !res.BudgetExhausted {
+		t.Fatalf("result not marked BudgetExhausted")
+	}
+	if n := atomic.LoadInt64(&stats.OK); n != 3 {
+		t.Fatalf("verified %d files, want 3", n)
+	}
+}
+
+func TestFileHashesHex_MatchesSinglePass(t *testing.T) {
+	dir := t.TempDir()
+	p := writeFile(t, dir, "file.bin", makeTestData(3<<20+7))
+
+	algorithms := []string{"SHA256", "md5", "SHA1", "sha256"}
+	got, err := FileHashesHex(p, algorithms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d digests, want 3: %v", len(got), got)
+	}
+	for _, alg := range []string{"SHA256", "MD5", "SHA1"} {
+		want, err := FileHashHex(p, alg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[alg] != want {
+			t.Errorf("%s: got %s want %s", alg, got[alg], want)
+		}
+	}
+
+	if _, err := FileHashesHex(p, []string{"SHA256", "CRC64"}, nil); err == nil {
+		t.Fatalf("unsupported algorithm accepted")
+	}
+}
+
+func TestVerify_ChecksEveryHash(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("one file, several digests")
+	p := writeFile(t, dir, "file.bin", content)
+	digests, err := FileHashesHex(p, []string{"SHA256", "MD5", "SHA1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		item      index.FileItem
+		disagreed []string
+	}{
+		{"all agree", index.FileItem{Hash: digests["SHA256"], Hashes: map[string]string{"md5": strings.ToLower(digests["MD5"]), "SHA1": digests["SHA1"]}}, nil},
+		{"extra disagrees", index.FileItem{Hash: digests["SHA256"], Hashes: map[string]string{"MD5": digests["SHA1"], "SHA1": digests["SHA1"]}}, []string{"MD5"}},
+		{"both disagree", index.FileItem{Hash: digests["MD5"], Hashes: map[string]string{"MD5": "00"}}, []string{"MD5", "SHA256"}},
+		{"primary only in hashes", index.FileItem{Hashes: map[string]string{"sha-256": digests["SHA256"], "MD5": digests["MD5"]}}, nil},
+		{"primary in hashes disagrees", index.FileItem{Hashes: map[string]string{"SHA256": digests["MD5"]}}, []string{"SHA256"}},
+		{"no primary digest", index.FileItem{Hashes: map[string]string{"MD5": digests["MD5"]}}, []string{"SHA256"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.item.Ok, tt.item.Path, tt.item.Length = true, p, int64(len(content))
+			stats := &metrics.Stats{}
+			res := Verify(context.Background(), "SHA256", []index.FileItem{tt.item}, Options{Workers: 1}, stats, nil)
+			if tt.disagreed == nil {
+				if stats.OK != 1 || len(res.Records) != 0 {
+					t.Fatalf("want OK, got records %+v", res.Records)
+				}
+				return
+			}
+			if len(res.Records) != 1 || res.Records[0].Outcome != OutcomeMismatch {
+				t.Fatalf("want one mismatch, got %+v", res.Records)
+			}
+			if got := res.Records[0].Disagreed; !slices.Equal(got, tt.disagreed) {
+				t.Fatalf("Disagreed = %v, want %v", got, tt.disagreed)
+			}
+			if got := res.Mismatches[0].Disagreed; !slices.Equal(got, tt.disagreed) {
+				t.Fatalf("Mismatch.Disagreed = %v, want %v", got, tt.disagreed)
+			}
+			if want := tt.item.Digests("SHA256")["SHA256"]; res.Mismatches[0].Expected != want {
+				t.Fatalf("Mismatch.Expected = %q, want %q", res.Mismatches[0].Expected, want)
+			}
+		})
+	}
+}